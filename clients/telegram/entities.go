@@ -0,0 +1,97 @@
+package tgClient
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	EntityURL      = "url"       // Ссылка в тексте: "https://example.com"
+	EntityTextLink = "text_link" // Ссылка, спрятанная под текстом
+
+	ChatTypeChannel = "channel"
+)
+
+// URLs() returns the links of the message text and of the media caption
+// in order of appearance, without duplicates
+func (m *IncomingMessage) URLs() []string {
+	urls := entityURLs(m.Text, m.Entities)
+
+	for _, link := range entityURLs(m.Caption, m.CaptionEntities) {
+		if !contains(urls, link) {
+			urls = append(urls, link)
+		}
+	}
+
+	return urls
+}
+
+// OriginalPostURL() returns the link to the channel post the message was forwarded from.
+// Messages forwarded from users and groups have no public link, so "" is returned
+func (m *IncomingMessage) OriginalPostURL() string {
+	chat, messageID := m.ForwardFromChat, m.ForwardFromMessageID
+	if m.ForwardOrigin != nil {
+		chat, messageID = m.ForwardOrigin.Chat, m.ForwardOrigin.MessageID
+	}
+
+	if chat == nil || messageID == 0 || chat.Type != ChatTypeChannel {
+		return ""
+	}
+
+	if chat.Username != "" {
+		return "https://t.me/" + chat.Username + "/" + strconv.Itoa(messageID)
+	}
+
+	// Ссылка на пост закрытого канала открывается только у его подписчиков.
+	// В ней используется id канала без префикса -100
+	id := strings.TrimPrefix(strconv.Itoa(chat.ID), "-100")
+
+	return "https://t.me/c/" + id + "/" + strconv.Itoa(messageID)
+}
+
+func contains(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// entityURLs() extracts links from "url" and "text_link" entities of the text.
+// Offsets of the entities are measured in UTF-16 code units
+func entityURLs(text string, entities []MessageEntity) []string {
+	var (
+		encoded []uint16
+		urls    []string
+		seen    = make(map[string]bool)
+	)
+
+	for _, entity := range entities {
+		var link string
+
+		switch entity.Type {
+		case EntityTextLink:
+			link = entity.URL
+		case EntityURL:
+			if encoded == nil {
+				encoded = utf16.Encode([]rune(text))
+			}
+			if entity.Offset < 0 || entity.Length <= 0 || entity.Offset+entity.Length > len(encoded) {
+				continue
+			}
+			link = string(utf16.Decode(encoded[entity.Offset : entity.Offset+entity.Length]))
+		default:
+			continue
+		}
+
+		if link != "" && !seen[link] {
+			seen[link] = true
+			urls = append(urls, link)
+		}
+	}
+
+	return urls
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
 
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/chunker"
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
 )
 
@@ -21,12 +23,31 @@ type Client struct {
 }
 
 const (
-	getUpdatesMethod          = "getUpdates"
-	sendMessageMethod         = "sendMessage"
-	AnswerCallbackQueryMethod = "answerCallbackQuery"
+	getUpdatesMethod             = "getUpdates"
+	sendMessageMethod            = "sendMessage"
+	editMessageTextMethod        = "editMessageText"
+	editMessageReplyMarkupMethod = "editMessageReplyMarkup"
+	deleteMessageMethod          = "deleteMessage"
+	sendDocumentMethod           = "sendDocument"
+	getFileMethod                = "getFile"
+	getMeMethod                  = "getMe"
+	getChatMemberMethod          = "getChatMember"
+	setMyCommandsMethod          = "setMyCommands"
+	answerInlineQueryMethod      = "answerInlineQuery"
+	AnswerCallbackQueryMethod    = "answerCallbackQuery"
 )
 
-var NoDataErr = errors.New("no data")
+// MaxMessageLength - ограничение Telegram на длину сообщения в UTF-16 code units
+const MaxMessageLength = 4096
+
+// MaxDownloadSize is the largest file that bots can download
+const MaxDownloadSize = 20 << 20
+
+var (
+	NoDataErr       = errors.New("no data")
+	ErrFileTooLarge = errors.New("file is too large")
+	ErrNotModified  = errors.New("message is not modified")
+)
 
 func New(host string, token string) *Client {
 	return &Client{
@@ -59,11 +80,41 @@ func (c *Client) Updates(offset int, limit int) ([]Update, error) {
 	return res.Result, nil
 }
 
+// SendMessage() sends the text. Long texts are split into several messages
+// on paragraph or line boundaries and sent in order
 func (c *Client) SendMessage(chatID int, text string) error {
+	return c.SendFormatted(chatID, text, "")
+}
+
+// SendFormatted() sends the text with markup in the given parse mode (HTML or MarkdownV2).
+// Every entity of the text must fit into one paragraph, since long texts are split on their boundaries
+func (c *Client) SendFormatted(chatID int, text string, parseMode string) error {
+	for _, chunk := range chunker.SplitText(text, MaxMessageLength) {
+		if err := c.sendMessage(chatID, chunk, parseMode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SendItems() sends the list, a single item is never split between two messages
+func (c *Client) SendItems(chatID int, items []string, parseMode string) error {
+	for _, chunk := range chunker.Split(items, MaxMessageLength) {
+		if err := c.sendMessage(chatID, chunk, parseMode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) sendMessage(chatID int, text string, parseMode string) error {
 
 	data := StandardMessage{
-		ChatID: chatID,
-		Text:   text,
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: parseMode,
 	}
 
 	// Get json
@@ -96,14 +147,35 @@ func (c *Client) SendCallbackMessage(chatID int, text string, list []string) err
 		buttons = append(buttons, inline)
 	}
 
+	return c.SendKeyboard(chatID, text, "", buttons)
+}
+
+// SendKeyboard() sends a message with an arbitrary inline keyboard.
+// If the text is too long, the keyboard is attached to its last part
+func (c *Client) SendKeyboard(chatID int, text string, parseMode string, buttons [][]InlineKeyboardButton) error {
+	if len(buttons) == 0 {
+		return NoDataErr
+	}
+
+	chunks := chunker.SplitText(text, MaxMessageLength)
+	if len(chunks) == 0 {
+		return NoDataErr
+	}
+
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if err := c.sendMessage(chatID, chunk, parseMode); err != nil {
+			return err
+		}
+	}
+	text = chunks[len(chunks)-1]
+
 	replyMarkup := InlineKeyboardMarkup{
 		InlineKeyboard: buttons}
 
-	log.Println(replyMarkup) // LOG
-
 	data := ReplyMessage{
 		ChatID:      chatID,
 		Text:        text,
+		ParseMode:   parseMode,
 		ReplyMarkup: replyMarkup,
 	}
 
@@ -121,9 +193,90 @@ func (c *Client) SendCallbackMessage(chatID int, text string, list []string) err
 	return nil
 }
 
+// EditMessageText() replaces the text and the keyboard of the sent message.
+// Without buttons the keyboard is removed. A text that doesn't fit into one message
+// can't be placed in the old one, so the old message is deleted and the text is sent anew
+func (c *Client) EditMessageText(chatID int, messageID int, text string, parseMode string, buttons [][]InlineKeyboardButton) error {
+	if chunker.Len(text) > MaxMessageLength {
+		if err := c.DeleteMessage(chatID, messageID); err != nil {
+			return err
+		}
+		if len(buttons) == 0 {
+			return c.SendFormatted(chatID, text, parseMode)
+		}
+		return c.SendKeyboard(chatID, text, parseMode, buttons)
+	}
+
+	data := EditMessage{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+		ParseMode: parseMode,
+	}
+	if len(buttons) != 0 {
+		data.ReplyMarkup = &InlineKeyboardMarkup{InlineKeyboard: buttons}
+	}
+
+	return c.editMessage(editMessageTextMethod, data)
+}
+
+// EditMessageReplyMarkup() replaces only the keyboard of the sent message.
+// Without buttons the keyboard is removed
+func (c *Client) EditMessageReplyMarkup(chatID int, messageID int, buttons [][]InlineKeyboardButton) error {
+	data := EditMessage{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+	if len(buttons) != 0 {
+		data.ReplyMarkup = &InlineKeyboardMarkup{InlineKeyboard: buttons}
+	}
+
+	return c.editMessage(editMessageReplyMarkupMethod, data)
+}
+
+func (c *Client) editMessage(method string, data EditMessage) error {
+	// Get json
+	EncodedData, err := json.Marshal(data)
+	if err != nil {
+		return errhandling.Wrap("can't get json", err)
+	}
+
+	_, err = c.doPostRequest(method, EncodedData)
+	// Сообщение уже выглядит так, как нужно
+	if errors.Is(err, ErrNotModified) {
+		return nil
+	}
+	if err != nil {
+		return errhandling.Wrap("can't edit a message", err)
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteMessage(chatID int, messageID int) error {
+	q := url.Values{}
+	q.Add("chat_id", strconv.Itoa(chatID))
+	q.Add("message_id", strconv.Itoa(messageID))
+
+	_, err := c.doGetRequest(deleteMessageMethod, q)
+	if err != nil {
+		return errhandling.Wrap("can't delete a message", err)
+	}
+
+	return nil
+}
+
 func (c *Client) AnswerCallbackQuery(CallbackQueryID string) error {
+	return c.AnswerCallbackQueryWithText(CallbackQueryID, "")
+}
+
+// AnswerCallbackQueryWithText() answers the query with a notification at the top of the chat
+func (c *Client) AnswerCallbackQueryWithText(CallbackQueryID string, text string) error {
 	q := url.Values{}
 	q.Add("callback_query_id", CallbackQueryID)
+	if text != "" {
+		q.Add("text", text)
+	}
 
 	_, err := c.doGetRequest(AnswerCallbackQueryMethod, q)
 	if err != nil {
@@ -133,6 +286,259 @@ func (c *Client) AnswerCallbackQuery(CallbackQueryID string) error {
 	return nil
 }
 
+// SendDocument() sends a file with the given name and contents
+func (c *Client) SendDocument(chatID int, fileName string, data []byte, caption string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't send document", err) }()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("chat_id", strconv.Itoa(chatID)); err != nil {
+		return err
+	}
+	if caption != "" {
+		if err := writer.WriteField("caption", caption); err != nil {
+			return err
+		}
+	}
+
+	part, err := writer.CreateFormFile("document", fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	_, err = c.doMultipartRequest(sendDocumentMethod, writer.FormDataContentType(), &body)
+
+	return err
+}
+
+// SetMyCommands() sets the command menu for users with the language in the scope.
+// Without the language the commands are shown to all users who have no commands for their language
+func (c *Client) SetMyCommands(commands []BotCommand, scope string, languageCode string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't set commands", err) }()
+
+	data, err := json.Marshal(SetMyCommands{
+		Commands:     commands,
+		Scope:        BotCommandScope{Type: scope},
+		LanguageCode: languageCode,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := c.doPostRequest(setMyCommandsMethod, data)
+	if err != nil {
+		return err
+	}
+
+	var res Response
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if !res.Ok {
+		return errors.New(res.Description)
+	}
+
+	return nil
+}
+
+// inlineCacheTime - сколько секунд Telegram может хранить результаты inline запроса.
+// Ссылки пользователя часто меняются, поэтому время небольшое
+const inlineCacheTime = 10
+
+// AnswerInlineQuery() sends the results of the inline query. Results are personal,
+// nextOffset is sent back by Telegram when the user scrolls to the end of the results
+func (c *Client) AnswerInlineQuery(queryID string, results []InlineQueryResultArticle, nextOffset string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't answer inline query", err) }()
+
+	if results == nil {
+		results = []InlineQueryResultArticle{}
+	}
+
+	data, err := json.Marshal(AnswerInlineQuery{
+		InlineQueryID: queryID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+		NextOffset:    nextOffset,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := c.doPostRequest(answerInlineQueryMethod, data)
+	if err != nil {
+		return err
+	}
+
+	var res Response
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if !res.Ok {
+		return errors.New(res.Description)
+	}
+
+	return nil
+}
+
+// GetFile() returns the path for downloading the file with the given id
+func (c *Client) GetFile(fileID string) (file *File, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get file", err) }()
+
+	q := url.Values{}
+	q.Add("file_id", fileID)
+
+	data, err := c.doGetRequest(getFileMethod, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var res FileResponse
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, errors.New(res.Description)
+	}
+
+	return &res.Result, nil
+}
+
+// GetMe() returns the bot itself, the username is needed to recognize commands addressed to the bot
+func (c *Client) GetMe() (me *From, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get bot info", err) }()
+
+	data, err := c.doGetRequest(getMeMethod, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res UserResponse
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, errors.New(res.Description)
+	}
+
+	return &res.Result, nil
+}
+
+// GetChatMember() returns the status of the user in the group
+func (c *Client) GetChatMember(chatID int, userID int) (member *ChatMember, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get chat member", err) }()
+
+	q := url.Values{}
+	q.Add("chat_id", strconv.Itoa(chatID))
+	q.Add("user_id", strconv.Itoa(userID))
+
+	data, err := c.doGetRequest(getChatMemberMethod, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var res ChatMemberResponse
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, errors.New(res.Description)
+	}
+
+	return &res.Result, nil
+}
+
+// DownloadFile() downloads the file with the given id, files larger than limit bytes aren't downloaded
+func (c *Client) DownloadFile(fileID string, limit int) (data []byte, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't download file", err) }()
+
+	file, err := c.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.FileSize > limit {
+		return nil, ErrFileTooLarge
+	}
+
+	u := url.URL{
+		Scheme: "https",
+		Host:   c.host,
+		Path:   path.Join("file", c.basePath, file.FilePath),
+	}
+
+	resp, err := c.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+
+	// Размер из getFile может отсутствовать, поэтому чтение тоже ограничено
+	data, err = io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, ErrFileTooLarge
+	}
+
+	return data, nil
+}
+
+// doMultipartRequest() sends a post request with a multipart/form-data body, used to upload files
+func (c *Client) doMultipartRequest(method string, contentType string, body io.Reader) (data []byte, err error) {
+
+	defer func() { err = errhandling.WrapIfErr("can't do request", err) }()
+
+	u := url.URL{
+		Scheme: "https",
+		Host:   c.host,
+		Path:   path.Join(c.basePath, method),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", contentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status + ": " + string(data))
+	}
+
+	return data, nil
+}
+
 // doPostRequest() sends a post request to the server. Accepts data in json format
 func (c *Client) doPostRequest(method string, jsonData []byte) (data []byte, err error) {
 
@@ -154,7 +560,6 @@ func (c *Client) doPostRequest(method string, jsonData []byte) (data []byte, err
 
 	// sending a request to the telegram api
 	resp, err := c.client.Do(req)
-	log.Println(resp.Status)
 	if err != nil {
 		return nil, err
 	}
@@ -166,14 +571,18 @@ func (c *Client) doPostRequest(method string, jsonData []byte) (data []byte, err
 		return nil, err
 	}
 
-	// var r UpdatesResponse
+	// Bot API сообщает об ошибке в ответе, а не только кодом статуса
+	var res Response
 
-	// err = json.Unmarshal(body, &r)
-	// log.Println(r.Result, r.Ok)
-
-	// if err != nil {
-	// 	return nil, err
-	// }
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, errors.New(resp.Status + ": " + string(body))
+	}
+	if !res.Ok {
+		if strings.Contains(res.Description, ErrNotModified.Error()) {
+			return nil, ErrNotModified
+		}
+		return nil, errors.New(res.Description)
+	}
 
 	return body, nil
 }
@@ -9,6 +9,38 @@ type Update struct {
 	ID            int              `json:"update_id"`
 	Message       *IncomingMessage `json:"message"`
 	CallbackQuery *CallbackQuery   `json:"callback_query"`
+	InlineQuery   *InlineQuery     `json:"inline_query"`
+}
+
+// InlineQuery is sent when the user types "@bot query" in any chat
+type InlineQuery struct {
+	ID     string `json:"id"`
+	From   From   `json:"from"`
+	Query  string `json:"query"`
+	Offset string `json:"offset"` // next_offset из предыдущего ответа на этот запрос
+}
+
+type InlineQueryResultArticle struct {
+	Type                string                  `json:"type"` // Всегда "article"
+	ID                  string                  `json:"id"`
+	Title               string                  `json:"title"`
+	Description         string                  `json:"description,omitempty"`
+	URL                 string                  `json:"url,omitempty"`
+	InputMessageContent InputTextMessageContent `json:"input_message_content"`
+}
+
+// InputTextMessageContent is the message that is sent when the user chooses the result
+type InputTextMessageContent struct {
+	MessageText string `json:"message_text"`
+	ParseMode   string `json:"parse_mode,omitempty"`
+}
+
+type AnswerInlineQuery struct {
+	InlineQueryID string                     `json:"inline_query_id"`
+	Results       []InlineQueryResultArticle `json:"results"`
+	CacheTime     int                        `json:"cache_time"`
+	IsPersonal    bool                       `json:"is_personal"`
+	NextOffset    string                     `json:"next_offset,omitempty"`
 }
 
 type CallbackQuery struct {
@@ -19,28 +51,127 @@ type CallbackQuery struct {
 }
 
 type IncomingMessage struct {
-	Text string `json:"text"`
-	From From   `json:"from"`
-	Chat Chat   `json:"chat"`
+	MessageID            int                   `json:"message_id"`
+	Text                 string                `json:"text"`
+	Entities             []MessageEntity       `json:"entities"`
+	Caption              string                `json:"caption"` // Подпись к фото, видео или документу
+	CaptionEntities      []MessageEntity       `json:"caption_entities"`
+	Document             *Document             `json:"document"`
+	ForwardOrigin        *MessageOrigin        `json:"forward_origin"`
+	ForwardFromChat      *Chat                 `json:"forward_from_chat"`       // Устаревший аналог forward_origin
+	ForwardFromMessageID int                   `json:"forward_from_message_id"` // Устаревший аналог forward_origin
+	From                 From                  `json:"from"`
+	Chat                 Chat                  `json:"chat"`
+	ReplyMarkup          *InlineKeyboardMarkup `json:"reply_markup"`
+}
+
+// MessageOrigin describes where the forwarded message came from
+type MessageOrigin struct {
+	Type       string `json:"type"`
+	Chat       *Chat  `json:"chat"`        // Для сообщений из каналов
+	MessageID  int    `json:"message_id"`  // Для сообщений из каналов
+	SenderChat *Chat  `json:"sender_chat"` // Для сообщений, отправленных от имени чата
+}
+
+// Document is a general file sent by the user
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+	FileSize int    `json:"file_size"`
+}
+
+// File is a file ready to be downloaded. FilePath is valid for at least an hour
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int    `json:"file_size"`
+	FilePath string `json:"file_path"`
+}
+
+type FileResponse struct {
+	Ok          bool   `json:"ok"`
+	Result      File   `json:"result"`
+	Description string `json:"description"`
+}
+
+type MessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
 }
 
 type From struct {
-	UserID int `json:"id"`
+	UserID       int    `json:"id"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"` // Язык интерфейса Telegram у пользователя
+}
+
+// Name() returns the name by which other users can recognize the user
+func (f From) Name() string {
+	if f.Username != "" {
+		return "@" + f.Username
+	}
+
+	return f.FirstName
 }
 
 type Chat struct {
-	ID int `json:"id"`
+	ID       int    `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+// IsGroup() tells whether the chat is a group or a supergroup
+func (c Chat) IsGroup() bool {
+	return c.Type == "group" || c.Type == "supergroup"
+}
+
+type UserResponse struct {
+	Ok          bool   `json:"ok"`
+	Result      From   `json:"result"`
+	Description string `json:"description"`
+}
+
+// ChatMember describes the user in the chat. Status is "creator", "administrator",
+// "member", "restricted", "left" or "kicked"
+type ChatMember struct {
+	Status string `json:"status"`
+	User   From   `json:"user"`
+}
+
+// IsAdmin() tells whether the member can manage the chat
+func (m ChatMember) IsAdmin() bool {
+	return m.Status == "creator" || m.Status == "administrator"
+}
+
+type ChatMemberResponse struct {
+	Ok          bool       `json:"ok"`
+	Result      ChatMember `json:"result"`
+	Description string     `json:"description"`
 }
 
 type ReplyMessage struct {
 	ChatID      int                  `json:"chat_id"`
 	Text        string               `json:"text"`
+	ParseMode   string               `json:"parse_mode,omitempty"`
 	ReplyMarkup InlineKeyboardMarkup `json:"reply_markup,omitempty"`
 }
 
 type StandardMessage struct {
-	ChatID int    `json:"chat_id"`
-	Text   string `json:"text"`
+	ChatID    int    `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type EditMessage struct {
+	ChatID      int                   `json:"chat_id"`
+	MessageID   int                   `json:"message_id"`
+	Text        string                `json:"text,omitempty"`
+	ParseMode   string                `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
 }
 
 type InlineKeyboardMarkup struct {
@@ -51,3 +182,32 @@ type InlineKeyboardButton struct {
 	Text         string `json:"text"`
 	CallbackData string `json:"callback_data"`
 }
+
+// BotCommand is a command shown in the menu next to the input field
+type BotCommand struct {
+	Command     string `json:"command"` // Без "/"
+	Description string `json:"description"`
+}
+
+// BotCommandScope defines the chats where the commands are shown
+type BotCommandScope struct {
+	Type string `json:"type"`
+}
+
+const (
+	ScopeDefault         = "default"
+	ScopeAllPrivateChats = "all_private_chats"
+	ScopeAllGroupChats   = "all_group_chats"
+)
+
+type SetMyCommands struct {
+	Commands     []BotCommand    `json:"commands"`
+	Scope        BotCommandScope `json:"scope"`
+	LanguageCode string          `json:"language_code,omitempty"`
+}
+
+// Response is the common part of all answers of the Bot API
+type Response struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
@@ -2,28 +2,26 @@ package telegram
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"strings"
 
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
 	conc "github.com/hahaclassic/golang-telegram-bot.git/lib/concatenation"
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
 )
 
 func (p *Processor) doCallbackCmd(text string, meta *CallbackMeta) (err error) {
 	defer func() {
-		switch p.sessions[meta.UserID].currentOperation {
-		case ChooseFolderForRenaming:
-			p.changeSessionData(meta.UserID, Session{text, RenameFolderCmd, statusProcessing})
-		case ChooseLinkForDeletionCmd:
-			p.changeSessionData(meta.UserID, Session{text, DeleteLinkCmd, statusProcessing})
-		default:
-			p.changeSessionData(meta.UserID, Session{text, "", statusOK})
-		}
-
 		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
 		if err != nil {
-			p.changeSessionData(meta.UserID, Session{"", "", statusOK})
+			p.states.Reset(meta.session())
 		}
-		if err == ErrEmptyFolder {
+		if err == ErrEmptyFolder || err == ErrNoFolders {
 			err = nil
 		}
 		err = errhandling.WrapIfErr("can't do callback cmd", err)
@@ -31,67 +29,330 @@ func (p *Processor) doCallbackCmd(text string, meta *CallbackMeta) (err error) {
 
 	text = strings.TrimSpace(text)
 
-	switch p.sessions[meta.UserID].currentOperation {
+	// При перелистывании страниц шаг операции не меняется
+	if page, ok := pageNumber(text); ok {
+		return p.turnPage(context.Background(), meta, page)
+	}
+
+	state, data := p.states.State(meta.session())
+
+	// next - данные, которые сохраняются для следующего шага
+	input, next := inputButton, text
+	if path, ok := navigationPath(text); ok {
+		input, next = inputNavigate, path
+	}
+	if _, _, ok := toggled(text); ok {
+		input, next = inputToggle, data
+	}
+
+	if !p.states.Can(meta.session(), input) {
+		if meta.Group {
+			// Клавиатура может принадлежать операции другого участника группы
+			return nil
+		}
+		// Операция уже завершена или отменена, старая клавиатура больше не нужна
+		return p.tg.DeleteMessage(meta.ChatID, meta.MessageID)
+	}
+
+	if err := p.handleButton(context.Background(), meta, state, data, text); err != nil {
+		return err
+	}
+
+	_, err = p.states.Fire(meta.session(), input, next)
+
+	return err
+}
+
+// handleButton() handles the button pressed on the step of the operation.
+// data is what the previous step has saved: the links to save, the chosen folder
+func (p *Processor) handleButton(ctx context.Context, meta *CallbackMeta, state fsm.State, data string, text string) error {
+	switch string(state) {
 	case SaveLinkCmd:
-		return p.savePage(context.Background(), meta, text)
+		return p.savePage(ctx, meta, data, text)
 
 	case ShowFolderCmd:
-		return p.showFolder(context.Background(), meta, text)
+		if path, ok := navigationPath(text); ok {
+			return p.sendFolderTree(ctx, meta.ChatID, meta.MessageID, meta.UserID, path, 0)
+		}
+		return p.showFolder(ctx, meta.ChatID, meta.MessageID, meta.UserID, text, 0)
 
 	case ChooseFolderForRenaming:
-		return p.chooseFolderForRenaming(meta.ChatID)
+		return p.chooseFolderForRenaming(meta)
 
 	case DeleteFolderCmd:
-		return p.deleteFolder(context.Background(), meta, text)
+		return p.deleteFolder(ctx, meta, text)
+
+	case MoveFolderCmd:
+		return p.chooseNewParent(ctx, meta, text, 0)
+
+	case MoveFolderToCmd:
+		return p.moveFolder(ctx, meta, data, text)
 
 	case ChooseLinkForDeletionCmd:
-		return p.chooseLinkForDeletion(context.Background(), meta, text)
+		return p.chooseLink(ctx, meta, text, 0)
 
 	case DeleteLinkCmd:
-		return p.deleteLink(context.Background(), meta, text)
+		return p.deleteLink(ctx, meta, text)
+
+	case ExportCmd:
+		return p.exportLinks(ctx, meta, text)
+
+	case ShareCmd:
+		return p.chooseRole(meta)
+
+	case ShareRoleCmd:
+		return p.createInvite(ctx, meta, data, text)
+
+	case SharedCmd:
+		if id, ok := sharedFolderID(text); ok {
+			return p.showShared(ctx, meta.ChatID, meta.MessageID, meta.UserID, id, 0)
+		}
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+
+	case RemindCmd:
+		return p.chooseLink(ctx, meta, text, 0)
+
+	case RemindLinkCmd:
+		return p.chooseRemindTime(ctx, meta)
+
+	case DigestCmd:
+		return p.chooseDigestHour(ctx, meta)
+
+	case DigestHourCmd:
+		return p.setDigestSchedule(ctx, meta, data, text)
+
+	case DigestFoldersCmd:
+		if id, page, ok := toggled(text); ok {
+			return p.toggleDigestFolder(ctx, meta, id, page)
+		}
+		return p.finishDigest(ctx, meta)
+
+	case DeadCmd:
+		if id, page, ok := toggled(text); ok {
+			return p.deleteDeadLink(ctx, meta, id, page)
+		}
+		if text == PurgeCmd {
+			return p.purgeDeadLinks(ctx, meta)
+		}
+		return p.tg.EditMessageReplyMarkup(meta.ChatID, meta.MessageID, nil)
+
+	case LanguageCmd:
+		return p.setLanguage(ctx, meta, text)
 	}
 
 	return nil
 }
 
-func (p *Processor) savePage(ctx context.Context, meta *CallbackMeta, folder string) (err error) {
-	defer func() { err = errhandling.WrapIfErr("can't save page", err) }()
+// turnPage() shows another page of the list that was sent during the current operation
+func (p *Processor) turnPage(ctx context.Context, meta *CallbackMeta, page int) error {
+	state, data := p.states.State(meta.session())
 
-	page := p.storage.NewPage(p.sessions[meta.UserID].lastMessage, meta.UserID, folder)
+	switch string(state) {
+	case SaveLinkCmd, ChooseFolderForRenaming, DeleteFolderCmd, ChooseLinkForDeletionCmd, MoveFolderCmd, ShareCmd, RemindCmd:
+		return p.chooseFolder(ctx, meta.ChatID, meta.MessageID, meta.UserID, state, page)
+	case SharedCmd:
+		return p.sendSharedFolders(ctx, meta.ChatID, meta.MessageID, meta.UserID, page)
+	case ShowFolderCmd:
+		return p.sendFolderTree(ctx, meta.ChatID, meta.MessageID, meta.UserID, data, page)
+	case MoveFolderToCmd:
+		return p.chooseNewParent(ctx, meta, data, page)
+	case DeleteLinkCmd, RemindLinkCmd:
+		return p.chooseLink(ctx, meta, data, page)
+	case DigestFoldersCmd:
+		return p.chooseDigestFolders(ctx, meta, page)
+	case DeadCmd:
+		return p.sendDeadLinks(ctx, meta.ChatID, meta.MessageID, meta.UserID, page)
+	}
+
+	return p.tg.DeleteMessage(meta.ChatID, meta.MessageID)
+}
 
-	isExists, err := p.storage.IsExist(ctx, page)
+// savePage() saves all links of the message to the chosen folder.
+// Links are compared in the canonical form, so the same page isn't saved twice.
+// Members of a shared folder are notified about the new links
+func (p *Processor) savePage(ctx context.Context, meta *CallbackMeta, packed string, target string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't save page", err) }()
+
+	ownerID, folder, err := p.sharedTarget(ctx, meta.UserID, target)
+	if errors.Is(err, storage.ErrAccessDenied) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgAccessDenied), nil)
+	}
 	if err != nil {
 		return err
 	}
-	if isExists {
-		return p.tg.SendMessage(meta.ChatID, msgAlreadyExists)
+	sharedID, shared := sharedFolderID(target)
+
+	links, sources, notes := unpackLinks(packed)
+	seen := make(map[string]bool)
+	var saved, otherFolders []string
+	var last *storage.Page
+
+	for i, link := range links {
+		if canonical, err := urlnorm.Canonical(link); err == nil {
+			link = canonical
+		}
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		page := p.storage.NewPage(link, ownerID, folder)
+		page.Source = sources[i]
+		page.Note = notes[i]
+
+		isExists, err := p.storage.IsExist(ctx, page)
+		if err != nil {
+			return err
+		}
+		if isExists {
+			continue
+		}
+
+		// Другие папки владельца участнику не показываются, только его собственные и общие
+		folders, err := p.linkFolders(ctx, meta.UserID, link)
+		if err != nil {
+			return err
+		}
+		otherFolders = appendMissing(otherFolders, folders...)
+
+		if shared {
+			if err := p.storage.SaveShared(ctx, meta.UserID, sharedID, page); err != nil {
+				return err
+			}
+		} else if err := p.storage.Save(ctx, page); err != nil {
+			return err
+		}
+		saved = append(saved, link)
+		last = page
 	}
 
-	if err := p.storage.Save(ctx, page); err != nil {
+	var message string
+
+	switch {
+	case len(seen) == 1 && len(saved) == 0:
+		message = p.text(meta.UserID, msgAlreadyExists)
+	case len(seen) == 1:
+		message = p.text(meta.UserID, msgSaved)
+	default:
+		message = p.text(meta.UserID, msgSavedSeveral, len(saved), len(seen)-len(saved))
+	}
+
+	if len(otherFolders) > 0 {
+		message += "\n\n" + p.text(meta.UserID, msgAlsoInFolders, strings.Join(otherFolders, ", "))
+	}
+
+	// Заметку к ссылке в общей папке может менять только владелец
+	var buttons [][]tgClient.InlineKeyboardButton
+	if len(seen) == 1 && last != nil && last.Note == "" && !shared {
+		buttons = p.noteKeyboard(last)
+	}
+
+	if err := p.show(meta.ChatID, meta.MessageID, message, buttons); err != nil {
 		return err
 	}
 
-	if err := p.tg.SendMessage(meta.ChatID, msgSaved); err != nil {
+	if len(saved) == 0 {
+		return nil
+	}
+
+	if err := p.snapshotLinks(ctx, ownerID, saved); err != nil {
 		return err
 	}
 
-	return nil
+	return p.notifyMembers(ctx, meta.UserID, meta.Name, ownerID, folder, saved)
 }
 
-func (p *Processor) showFolder(ctx context.Context, meta *CallbackMeta, folder string) error {
+// linkFolders() returns the folders of the user and the shared folders where the link is saved
+func (p *Processor) linkFolders(ctx context.Context, userID int, url string) ([]string, error) {
+	folders, err := p.storage.FindFolders(ctx, userID, url)
+	if err != nil {
+		return nil, err
+	}
 
-	urls, err := p.storage.GetFolder(ctx, meta.UserID, folder)
+	shared, err := p.storage.FindSharedFolders(ctx, userID, url)
 	if err != nil {
-		return errhandling.Wrap("can't show folder", err)
+		return nil, err
+	}
+	for _, folder := range shared {
+		folders = append(folders, btnShared+folder.Folder)
 	}
 
-	if len(urls) == 0 {
-		return p.tg.SendMessage(meta.ChatID, msgEmptyFolder)
+	return folders, nil
+}
+
+// appendMissing() appends the elements that aren't in the list yet
+func appendMissing(list []string, elems ...string) []string {
+	for _, elem := range elems {
+		if !contains(list, elem) {
+			list = append(list, elem)
+		}
 	}
 
-	result := folder + ":\n" + conc.EnumeratedJoin(urls)
+	return list
+}
+
+func (p *Processor) showFolder(ctx context.Context, chatID int, messageID int, userID int, folder string, page int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't show folder", err) }()
+
+	count, err := p.storage.CountPages(ctx, userID, folder)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return p.show(chatID, messageID, p.text(userID, msgEmptyFolder), nil)
+	}
+
+	pg := newPagination(page, p.pageSize, count)
 
-	return p.tg.SendMessage(meta.ChatID, result)
+	pages, err := p.storage.GetPages(ctx, userID, folder, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	var data func(page int) string
+	if pg.total > 1 {
+		folderID, err := p.storage.GetFolderID(ctx, userID, folder)
+		if err != nil {
+			return err
+		}
+		data = folderPageData(folderID)
+	}
+
+	return p.showLinks(chatID, messageID, userID, folder, pages, pg, data)
+}
+
+// showLinks() shows one page of the folder contents under the header.
+// data builds callback data of the pagination buttons, it's needed only for several pages
+func (p *Processor) showLinks(chatID int, messageID int, userID int, header string, pages []*storage.Page, pg pagination, data func(page int) string) error {
+	links := make([]string, 0, len(pages))
+	for _, link := range pages {
+		links = append(links, formatPage(link, p.text(userID, msgOriginalPost)))
+	}
+
+	items := append([]string{folderHeader(header)}, conc.Enumerate(links, pg.offset()+1)...)
+
+	if pg.total == 1 {
+		if messageID == 0 {
+			return p.tg.SendItems(chatID, items, format.ParseMode())
+		}
+		return p.showFormatted(chatID, messageID, strings.Join(items, ""), format.ParseMode(), nil)
+	}
+
+	return p.showFormatted(chatID, messageID, strings.Join(items, ""), format.ParseMode(), pg.withPages(nil, data))
+}
+
+// turnFolderPage() shows another page of the folder contents
+func (p *Processor) turnFolderPage(ctx context.Context, meta *CallbackMeta, folderID int, page int) error {
+	folder, err := p.storage.GetFolderByID(ctx, meta.UserID, folderID)
+	if errors.Is(err, storage.ErrFolderNotFound) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgFolderNotExists), nil)
+	}
+	if err != nil {
+		return errhandling.Wrap("can't turn folder page", err)
+	}
+
+	return p.showFolder(ctx, meta.ChatID, meta.MessageID, meta.UserID, folder, page)
 }
 
 func (p *Processor) deleteFolder(ctx context.Context, meta *CallbackMeta, folder string) error {
@@ -101,36 +362,302 @@ func (p *Processor) deleteFolder(ctx context.Context, meta *CallbackMeta, folder
 		return errhandling.Wrap("can't delete folder", err)
 	}
 
-	return p.tg.SendMessage(meta.ChatID, msgFolderDeleted)
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgFolderDeleted), nil)
+}
+
+func (p *Processor) chooseFolderForRenaming(meta *CallbackMeta) error {
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgEnterNewFolderName), nil)
+}
+
+// chooseNewParent() offers all folders where the folder can be moved
+func (p *Processor) chooseNewParent(ctx context.Context, meta *CallbackMeta, folder string, page int) (err error) {
+	defer func() {
+		if err != ErrNoFolders {
+			err = errhandling.WrapIfErr("can't choose new parent folder", err)
+		}
+	}()
+
+	folders, err := p.storage.GetListOfFolders(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	buttons := [][]tgClient.InlineKeyboardButton{}
+	if folderpath.Parent(folder) != "" {
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: p.text(meta.UserID, btnRootFolder), CallbackData: RootFolderCmd}})
+	}
+
+	for _, target := range folders {
+		if folderpath.IsInside(target, folder) || target == folderpath.Parent(folder) {
+			continue
+		}
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: target, CallbackData: target}})
+	}
+
+	if len(buttons) == 0 {
+		_ = p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgNoFolders), nil)
+		return ErrNoFolders
+	}
+
+	pg := newPagination(page, p.pageSize, len(buttons))
+	from, to := pg.bounds(len(buttons))
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgChooseNewParent), pg.withPages(buttons[from:to], pageData))
 }
 
-func (p *Processor) chooseFolderForRenaming(chatID int) error {
-	return p.tg.SendMessage(chatID, msgEnterNewFolderName)
+func (p *Processor) moveFolder(ctx context.Context, meta *CallbackMeta, oldFolder string, parent string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't move folder", err) }()
+
+	if parent == RootFolderCmd {
+		parent = ""
+	}
+
+	folder := folderpath.Join(parent, folderpath.Base(oldFolder))
+
+	if folderpath.IsInside(parent, oldFolder) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgCantMove), nil)
+	}
+	if len(folder) > maxFolderPathLength {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgLongFolderName), nil)
+	}
+
+	ok, err := p.storage.IsFolderExist(ctx, meta.UserID, folder)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgCantMove), nil)
+	}
+
+	if err := p.storage.RenameFolder(ctx, meta.UserID, folder, oldFolder); err != nil {
+		return err
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgFolderMoved), nil)
 }
 
-func (p *Processor) chooseLinkForDeletion(ctx context.Context, meta *CallbackMeta, folder string) error {
+// sendFolderTree() sends the subfolders of the path with a breadcrumb navigation.
+// Folders with subfolders are opened as a new level, the others show their links
+func (p *Processor) sendFolderTree(ctx context.Context, chatID int, messageID int, userID int, path string, page int) (err error) {
+	defer func() {
+		if err != ErrNoFolders {
+			err = errhandling.WrapIfErr("can't send folder tree", err)
+		}
+	}()
+
+	count, err := p.storage.CountSubfolders(ctx, userID, path)
+	if err != nil {
+		return err
+	}
+	if path == "" && count == 0 {
+		_ = p.show(chatID, messageID, p.text(userID, msgNoFolders), nil)
+		return ErrNoFolders
+	}
+
+	pg := newPagination(page, p.pageSize, count)
+
+	folders, err := p.storage.GetSubfolders(ctx, userID, path, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
 
-	urls, err := p.storage.GetFolder(ctx, meta.UserID, folder)
+	unread, err := p.storage.CountUnread(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	text := p.text(userID, msgChooseFolder)
+	buttons := [][]tgClient.InlineKeyboardButton{}
+
+	if path != "" {
+		text += ": " + path
+
+		breadcrumb := []tgClient.InlineKeyboardButton{{Text: btnRoot, CallbackData: NavigateCmd}}
+		ancestors := folderpath.Ancestors(path)
+		for _, ancestor := range ancestors[:len(ancestors)-1] {
+			breadcrumb = append(breadcrumb, tgClient.InlineKeyboardButton{
+				Text:         folderpath.Base(ancestor),
+				CallbackData: navigationData(ancestor),
+			})
+		}
+
+		buttons = append(buttons, breadcrumb,
+			[]tgClient.InlineKeyboardButton{{Text: p.text(userID, btnOpenFolder), CallbackData: path}})
+	}
+
+	for _, folder := range folders {
+		button := tgClient.InlineKeyboardButton{Text: folderpath.Base(folder.Path), CallbackData: folder.Path}
+		if folder.HasSubfolders {
+			button.Text = btnSubfolders + button.Text
+			button.CallbackData = navigationData(folder.Path)
+		}
+		if count := unreadInside(unread, folder.Path); count > 0 {
+			button.Text += " (" + strconv.Itoa(count) + ")"
+		}
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{button})
+	}
+
+	return p.show(chatID, messageID, text, pg.withPages(buttons, pageData))
+}
+
+// unreadInside() sums unread counters of the folder and all its subfolders
+func unreadInside(unread map[string]int, folder string) (count int) {
+	for path, n := range unread {
+		if folderpath.IsInside(path, folder) {
+			count += n
+		}
+	}
+
+	return count
+}
+
+func navigationData(path string) string {
+	return NavigateCmd + " " + path
+}
+
+// navigationPath() parses callback data of the folder tree buttons
+func navigationPath(data string) (string, bool) {
+	if data == NavigateCmd {
+		return "", true
+	}
+
+	return strings.CutPrefix(data, NavigateCmd+" ")
+}
+
+// chooseLink() shows the links of the folder as buttons for the current operation
+func (p *Processor) chooseLink(ctx context.Context, meta *CallbackMeta, folder string, page int) error {
+
+	count, err := p.storage.CountPages(ctx, meta.UserID, folder)
 	if err != nil {
 		return errhandling.Wrap("can't show folder", err)
 	}
 
-	if len(urls) == 0 {
-		p.tg.SendMessage(meta.ChatID, msgEmptyFolder)
+	if count == 0 {
+		p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgEmptyFolder), nil)
 		return ErrEmptyFolder
 	}
 
-	return p.tg.SendCallbackMessage(meta.ChatID, msgChooseLink, urls)
+	pg := newPagination(page, p.pageSize, count)
+
+	pages, err := p.storage.GetPages(ctx, meta.UserID, folder, pg.size, pg.offset())
+	if err != nil {
+		return errhandling.Wrap("can't show folder", err)
+	}
+
+	// В callback data передается id ссылки: сама ссылка может не поместиться в 64 байта
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(pages)+1)
+	for _, link := range pages {
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: link.URL, CallbackData: strconv.Itoa(link.ID)}})
+	}
+
+	prompt := msgChooseLink
+	if state, _ := p.states.State(meta.session()); state == fsm.State(RemindCmd) || state == fsm.State(RemindLinkCmd) {
+		prompt = msgChooseRemindLink
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, prompt), pg.withPages(buttons, pageData))
 }
 
 func (p *Processor) deleteLink(ctx context.Context, meta *CallbackMeta, link string) error {
 
-	page := p.storage.NewPage(link, meta.UserID, p.sessions[meta.UserID].lastMessage)
+	id, err := strconv.Atoi(link)
+	if err != nil {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgPageNotFound), nil)
+	}
+
+	page, err := p.storage.GetPage(ctx, meta.UserID, id)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgPageNotFound), nil)
+	}
+	if err != nil {
+		return err
+	}
 
-	err := p.storage.Remove(ctx, page)
+	err = p.storage.Remove(ctx, page)
 	if err != nil {
 		return err
 	}
 
-	return p.tg.SendMessage(meta.ChatID, msgPageDeleted)
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgPageDeleted), nil)
+}
+
+// changeStatus() handles "Mark read" and "Archive" buttons under the link.
+// The buttons of the link are removed from the keyboard, the result is shown as a notification
+func (p *Processor) changeStatus(ctx context.Context, meta *CallbackMeta, cmd string, id int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't change page status", err) }()
+
+	status, message := storage.StatusRead, p.text(meta.UserID, msgMarkedRead)
+	if cmd == ArchiveCmd {
+		status, message = storage.StatusArchived, p.text(meta.UserID, msgArchived)
+	}
+
+	err = p.storage.SetStatus(ctx, meta.UserID, id, status)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		message, err = p.text(meta.UserID, msgPageNotFound), nil
+	}
+	if err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+
+	if err := p.tg.AnswerCallbackQueryWithText(meta.QueryID, message); err != nil {
+		return err
+	}
+
+	return p.tg.EditMessageReplyMarkup(meta.ChatID, meta.MessageID, withoutPageActions(meta.Keyboard, id))
+}
+
+// withoutPageActions() returns the keyboard without rows that contain status buttons of the page.
+// The note and the reminder can still be changed, the copy can still be opened
+func withoutPageActions(keyboard [][]tgClient.InlineKeyboardButton, id int) [][]tgClient.InlineKeyboardButton {
+	res := make([][]tgClient.InlineKeyboardButton, 0, len(keyboard))
+
+	for _, row := range keyboard {
+		keep := true
+		for _, button := range row {
+			if cmd, pageID, ok := pageAction(button.CallbackData); ok && pageID == id && cmd != NoteCmd && cmd != RemindTimeCmd && cmd != SnapshotCmd {
+				keep = false
+			}
+		}
+		if keep {
+			res = append(res, row)
+		}
+	}
+
+	return res
+}
+
+// pageActionsKeyboard() returns buttons that are shown under a single link
+func (p *Processor) pageActionsKeyboard(page *storage.Page) [][]tgClient.InlineKeyboardButton {
+	keyboard := [][]tgClient.InlineKeyboardButton{
+		{
+			{Text: p.text(page.UserID, btnMarkRead), CallbackData: pageActionData(MarkReadCmd, page.ID)},
+			{Text: p.text(page.UserID, btnArchive), CallbackData: pageActionData(ArchiveCmd, page.ID)},
+		},
+		{p.noteButton(page), p.remindButton(page)},
+	}
+	if page.Snapshot {
+		keyboard = append(keyboard, []tgClient.InlineKeyboardButton{p.snapshotButton(page)})
+	}
+
+	return keyboard
+}
+
+func pageActionData(cmd string, id int) string {
+	return cmd + " " + strconv.Itoa(id)
+}
+
+// pageAction() parses callback data of the page buttons
+func pageAction(data string) (cmd string, id int, ok bool) {
+	cmd, arg, found := strings.Cut(data, " ")
+	if !found || (cmd != MarkReadCmd && cmd != ArchiveCmd && cmd != NoteCmd && cmd != RemindTimeCmd && cmd != SnapshotCmd) {
+		return "", 0, false
+	}
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return cmd, id, true
 }
@@ -3,183 +3,325 @@ package telegram
 import (
 	"context"
 	"errors"
-	"log"
-	"net/url"
+	"strconv"
 	"strings"
 
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	conc "github.com/hahaclassic/golang-telegram-bot.git/lib/concatenation"
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/i18n"
 	"github.com/hahaclassic/golang-telegram-bot.git/storage"
 )
 
-func (p *Processor) doCmd(text string, chatID int, userID int) (err error) {
+// routes() registers handlers of all message commands and operation inputs
+func (p *Processor) routes() *router {
+	rt := newRouter(p.states, p.recovery, p.metrics, p.logging, p.authorization, p.rateLimit, p.adminOnly)
 
-	defer func() {
-		if err != nil {
-			p.changeSessionData(userID, Session{"", "", statusOK})
-			return
-		}
-		if err == ErrNoFolders {
-			err = nil
-			return
-		}
+	rt.global[CancelCmd] = func(ctx context.Context, r *request) error {
+		return p.cancelOperation(r)
+	}
 
-		if p.sessions[userID].currentOperation == RenameFolderCmd {
-			p.changeSessionData(userID, Session{"", "", statusOK})
+	rt.commands[StartCmd] = func(ctx context.Context, r *request) error {
+		if r.args != "" {
+			// Ссылка t.me/<bot>?start=<code> открывает бота с кодом приглашения
+			return p.joinFolder(ctx, r.chatID, r.userID, r.message.Name, r.args)
 		}
-	}()
-
-	text = strings.TrimSpace(text)
-	if len(text) > 60 {
-		return p.tg.SendMessage(chatID, msgLongMessage)
+		return p.sendHello(r.chatID, r.userID)
 	}
-
-	log.Printf("got new command '%s' from '%d'", text, userID)
-
-	if text == CancelCmd {
-		return p.cancelOperation(chatID, userID)
+	rt.commands[JoinCmd] = func(ctx context.Context, r *request) error {
+		return p.joinFolder(ctx, r.chatID, r.userID, r.message.Name, r.args)
+	}
+	rt.commands[CaptureCmd] = p.toggleCapture
+	rt.commands[SnapshotsCmd] = p.toggleSnapshots
+	rt.commands[StatsCmd] = p.sendStats
+	rt.commands[RusHelpCmd] = func(ctx context.Context, r *request) error {
+		return p.sendRusHelp(r.chatID)
+	}
+	rt.commands[HelpCmd] = func(ctx context.Context, r *request) error {
+		return p.sendHelp(r.chatID, r.userID)
+	}
+	rt.commands[RndCmd] = func(ctx context.Context, r *request) error {
+		return p.sendRandom(ctx, r.chatID, r.userID)
+	}
+	rt.commands[UnreadCmd] = func(ctx context.Context, r *request) error {
+		return p.sendUnread(ctx, r.chatID, 0, r.userID, 0)
 	}
 
-	if p.sessions[userID].status {
-
-		if isAddCmd(text) {
-			p.changeSessionData(userID, Session{text, SaveLinkCmd, statusProcessing})
-			return p.chooseFolder(context.Background(), chatID, userID)
+	rt.commands[ExportCmd] = p.operation(ExportCmd, func(ctx context.Context, r *request) error {
+		return p.show(r.chatID, 0, p.text(r.userID, msgChooseFormat), p.formatsKeyboard(r.userID))
+	})
+	rt.commands[ImportCmd] = p.operation(ImportCmd, func(ctx context.Context, r *request) error {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgSendFile))
+	})
+	rt.commands[LanguageCmd] = p.operation(LanguageCmd, func(ctx context.Context, r *request) error {
+		return p.show(r.chatID, 0, p.text(r.userID, msgChooseLanguage), languagesKeyboard())
+	})
+	rt.commands[ShowFolderCmd] = p.operation(ShowFolderCmd, func(ctx context.Context, r *request) error {
+		return p.sendFolderTree(ctx, r.chatID, 0, r.userID, "", 0)
+	})
+	rt.commands[CreateFolderCmd] = p.operation(CreateFolderCmd, func(ctx context.Context, r *request) error {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgEnterFolderName))
+	})
+	rt.commands[SharedCmd] = p.operation(SharedCmd, func(ctx context.Context, r *request) error {
+		return p.sendSharedFolders(ctx, r.chatID, 0, r.userID, 0)
+	})
+	rt.commands[TimezoneCmd] = p.operation(TimezoneCmd, p.askTimezone)
+	rt.commands[DigestCmd] = func(ctx context.Context, r *request) error {
+		if strings.EqualFold(r.args, digestOff) {
+			return p.disableDigest(ctx, r)
 		}
+		return p.operation(DigestCmd, p.askDigestDay)(ctx, r)
+	}
+	rt.commands[DeadCmd] = func(ctx context.Context, r *request) error {
+		count, err := p.storage.CountDeadPages(ctx, r.userID)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return p.tg.SendMessage(r.chatID, p.text(r.userID, msgNoDeadLinks))
+		}
+		return p.operation(DeadCmd, func(ctx context.Context, r *request) error {
+			return p.sendDeadLinks(ctx, r.chatID, 0, r.userID, 0)
+		})(ctx, r)
+	}
 
-		switch text {
-		case StartCmd:
-			return p.sendHello(chatID)
-		case RusHelpCmd:
-			return p.sendRusHelp(chatID)
-		case HelpCmd:
-			return p.sendHelp(chatID)
-		case RndCmd:
-			return p.sendRandom(context.Background(), chatID, userID)
-
-		case ShowFolderCmd:
-			p.changeSessionData(userID, Session{"", ShowFolderCmd, statusProcessing})
-			return p.chooseFolder(context.Background(), chatID, userID)
-
-		case CreateFolderCmd:
-			p.changeSessionData(userID, Session{"", CreateFolderCmd, statusProcessing})
-			return p.tg.SendMessage(chatID, msgEnterFolderName)
-
-		case ChooseFolderForRenaming:
-			p.changeSessionData(userID, Session{"", ChooseFolderForRenaming, statusProcessing})
-			return p.chooseFolder(context.Background(), chatID, userID)
-
-		case DeleteFolderCmd:
-			p.changeSessionData(userID, Session{"", DeleteFolderCmd, statusProcessing})
-			return p.chooseFolder(context.Background(), chatID, userID)
+	// Эти операции начинаются с выбора папки
+	for _, cmd := range []string{ChooseFolderForRenaming, MoveFolderCmd, DeleteFolderCmd, ChooseLinkForDeletionCmd, ShareCmd, RemindCmd} {
+		rt.commands[cmd] = p.operation(cmd, p.firstFolderPage)
+	}
 
-		case ChooseLinkForDeletionCmd:
-			p.changeSessionData(userID, Session{"", ChooseLinkForDeletionCmd, statusProcessing})
-			return p.chooseFolder(context.Background(), chatID, userID)
+	rt.links = func(ctx context.Context, r *request) error {
+		packed := packLinks(r.links, r.message.Source, p.messageNote(r.text, r.links))
+		if _, err := p.states.Fire(r.session(), inputLinks, packed); err != nil {
+			return err
+		}
+		return p.firstFolderPage(ctx, r)
+	}
 
-		default:
-			return p.tg.SendMessage(chatID, msgUnknownCommand)
+	rt.inputs[fsm.State(CreateFolderCmd)] = func(ctx context.Context, r *request) error {
+		if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+			return err
 		}
+		return p.createFolder(ctx, r.chatID, r.userID, folderpath.Clean(r.text)) // text == folderName
+	}
+	rt.inputs[fsm.State(RenameFolderCmd)] = func(ctx context.Context, r *request) error {
+		_, oldFolder := p.states.State(r.session())
+		if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+			return err
+		}
+		return p.renameFolder(ctx, r.chatID, r.userID, oldFolder, r.text)
+	}
+	rt.inputs[fsm.State(NoteCmd)] = func(ctx context.Context, r *request) error {
+		_, id := p.states.State(r.session())
+		if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+			return err
+		}
+		return p.setNote(ctx, r.chatID, r.userID, id, r.text)
+	}
+	rt.inputs[fsm.State(RemindTimeCmd)] = func(ctx context.Context, r *request) error {
+		_, id := p.states.State(r.session())
+		return p.remind(ctx, r, id)
+	}
+	rt.inputs[fsm.State(TimezoneCmd)] = p.setTimezone
+	rt.inputs[fsm.State(ImportCmd)] = func(ctx context.Context, r *request) error {
+		if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+			return err
+		}
+		return p.importLinks(ctx, r.chatID, r.userID, r.message.Document)
+	}
 
-	} else {
+	rt.unknown = func(ctx context.Context, r *request) error {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgUnknownCommand))
+	}
+	rt.unexpected = func(ctx context.Context, r *request) error {
+		return p.unknownCommandHelp(r)
+	}
 
-		switch p.sessions[userID].currentOperation {
+	return rt
+}
 
-		case CreateFolderCmd:
-			p.changeSessionData(userID, Session{"", "", statusOK})
-			return p.createFolder(context.Background(), chatID, userID, text) // text == folderName
+// operation() returns the handler that starts the operation and sends its first step
+func (p *Processor) operation(name string, first handler) handler {
+	return func(ctx context.Context, r *request) error {
+		if _, err := p.states.Fire(r.session(), fsm.Input(name), ""); err != nil {
+			return err
+		}
+		return first(ctx, r)
+	}
+}
 
-		case RenameFolderCmd:
-			return p.renameFolder(context.Background(), chatID, userID, text)
+func (p *Processor) firstFolderPage(ctx context.Context, r *request) error {
+	state, _ := p.states.State(r.session())
+	return p.chooseFolder(ctx, r.chatID, 0, r.userID, state, 0)
+}
 
-		default:
-			return p.unknownCommandHelp(chatID, userID)
-		}
+// messageLinks() returns the links of the message that can be saved
+func (p *Processor) messageLinks(text string, meta *Meta) []string {
+	links := p.linksToSave(text, meta.URLs)
+	if len(links) == 0 && meta.Source != "" {
+		// В пересланном посте нет ссылок, сохраняется ссылка на сам пост
+		links = []string{meta.Source}
 	}
+
+	return links
 }
 
-func (p *Processor) cancelOperation(chatID int, userID int) error {
-	p.changeSessionData(userID, Session{"", "", statusOK})
-	return p.tg.SendMessage(chatID, msgOperationCancelled)
+func (p *Processor) cancelOperation(r *request) error {
+	p.states.Reset(r.session())
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgOperationCancelled))
 }
 
-func (p *Processor) unknownCommandHelp(chatID int, userID int) error {
+// Подсказки к операциям, которые ждут от пользователя нажатия кнопки
+var operationHints = map[string]msgKey{
+	ChooseFolderForRenaming:  msgHintRename,
+	MoveFolderCmd:            msgHintMove,
+	MoveFolderToCmd:          msgHintMoveTo,
+	ChooseLinkForDeletionCmd: msgHintDeleteLinkFolder,
+	ShowFolderCmd:            msgHintShow,
+	DeleteFolderCmd:          msgHintDeleteFolder,
+	ExportCmd:                msgHintExport,
+	ImportCmd:                msgHintImport,
+	LanguageCmd:              msgHintLanguage,
+	ShareCmd:                 msgHintShare,
+	ShareRoleCmd:             msgHintShareRole,
+	SharedCmd:                msgHintShared,
+	NoteCmd:                  msgHintNote,
+	RemindCmd:                msgHintRemind,
+	RemindLinkCmd:            msgHintRemindLink,
+	RemindTimeCmd:            msgHintRemindTime,
+	TimezoneCmd:              msgHintTimezone,
+	DigestCmd:                msgHintDigest,
+	DigestHourCmd:            msgHintDigestHour,
+	DigestFoldersCmd:         msgHintDigestFolders,
+	DeadCmd:                  msgHintDead,
+}
 
-	var message string = msgUnexpectedCommand + "\n\n"
-	var msgCancel string = "or enter /cancel to abort operation."
+func (p *Processor) unknownCommandHelp(r *request) error {
+	message := p.text(r.userID, msgUnexpectedCommand)
+	state, _ := p.states.State(r.session())
+	operation := string(state)
 
-	switch p.sessions[userID].currentOperation {
-	case ChooseFolderForRenaming:
-		message += "Select the folder you want to rename " + msgCancel
-	case ChooseLinkForDeletionCmd:
-		message += "Select the folder where you want to delete the link " + msgCancel
-	case DeleteLinkCmd:
-		message += "Select the link you want to delete "
-	// case CreateFolderCmd:
-	// 	message += "Enter new folder's name " + msgCancel
-	case ShowFolderCmd:
-		message += "Select the folder whose contents you want to see " + msgCancel
-	case DeleteFolderCmd:
-		message += "Select the folder you want to delete " + msgCancel
-	default:
-		message = msgUnexpectedCommand
+	if operation == DeleteLinkCmd {
+		message += "\n\n" + p.text(r.userID, msgHintDeleteLink)
+	} else if hint, ok := operationHints[operation]; ok {
+		message += "\n\n" + p.text(r.userID, hint) + " " + p.text(r.userID, msgHintCancel)
 	}
 
-	return p.tg.SendMessage(chatID, message)
+	return p.tg.SendMessage(r.chatID, message)
 }
 
 func (p *Processor) createFolder(ctx context.Context, chatID int, userID int, folder string) (err error) {
 	defer func() { err = errhandling.WrapIfErr("can't create folder", err) }()
 
+	if folder == "" {
+		return p.tg.SendMessage(chatID, p.text(userID, msgEmptyFolderName))
+	}
+	if len(folder) > maxFolderPathLength {
+		return p.tg.SendMessage(chatID, p.text(userID, msgLongFolderName))
+	}
+
 	ok, err := p.storage.IsFolderExist(ctx, userID, folder)
 	if err != nil {
 		return err
 	}
 
 	if ok {
-		p.tg.SendMessage(chatID, msgFolderAlreadyExists)
+		p.tg.SendMessage(chatID, p.text(userID, msgFolderAlreadyExists))
 	} else {
 		p.storage.NewFolder(ctx, userID, folder)
-		p.tg.SendMessage(chatID, msgNewFolderCreated)
+		p.tg.SendMessage(chatID, p.text(userID, msgNewFolderCreated))
 	}
 
 	return nil
 }
 
-func (p *Processor) chooseFolder(ctx context.Context, chatID int, userID int) (err error) {
+// chooseFolder() sends the folder list or, if messageID isn't 0, shows it in place of that message.
+// If the operation in state works with shared folders, they follow the folders of the user
+func (p *Processor) chooseFolder(ctx context.Context, chatID int, messageID int, userID int, state fsm.State, page int) (err error) {
 	defer func() {
 		if err != ErrNoFolders {
 			err = errhandling.WrapIfErr("can't do command: choose folder", err)
 		}
 	}()
 
-	folders, err := p.storage.GetListOfFolders(ctx, userID)
+	count, err := p.storage.CountFolders(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	shared, err := p.sharedButtons(ctx, userID, sharedRoles[string(state)])
 	if err != nil {
 		return err
 	}
-	if len(folders) == 0 {
-		_ = p.tg.SendMessage(chatID, msgNoFolders)
+
+	if count+len(shared) == 0 {
+		_ = p.show(chatID, messageID, p.text(userID, msgNoFolders), nil)
 		return ErrNoFolders
 	}
 
-	return p.tg.SendCallbackMessage(chatID, msgChooseFolder, folders)
+	pg := newPagination(page, p.pageSize, count+len(shared))
+
+	folders, err := p.storage.GetFoldersPage(ctx, userID, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	unread, err := p.storage.CountUnread(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(folders)+1)
+	for _, folder := range folders {
+		text := folder
+		if unread[folder] > 0 {
+			text += " (" + strconv.Itoa(unread[folder]) + ")"
+		}
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: text, CallbackData: folder}})
+	}
+
+	// Индексы общих папок на текущей странице общего списка
+	from, to := pg.bounds(count + len(shared))
+	if from -= count; from < 0 {
+		from = 0
+	}
+	if to -= count; to > from {
+		buttons = append(buttons, shared[from:to]...)
+	}
+
+	return p.show(chatID, messageID, p.text(userID, msgChooseFolder), pg.withPages(buttons, pageData))
 }
 
-func (p *Processor) renameFolder(ctx context.Context, chatID int, userID int, folder string) error {
+func (p *Processor) renameFolder(ctx context.Context, chatID int, userID int, oldFolder string, name string) error {
+
+	if name == "" {
+		return p.tg.SendMessage(chatID, p.text(userID, msgEmptyFolderName))
+	}
+	if strings.Contains(name, folderpath.Separator) {
+		return p.tg.SendMessage(chatID, p.text(userID, msgSlashInName))
+	}
+
+	folder := folderpath.Join(folderpath.Parent(oldFolder), name)
+	if len(folder) > maxFolderPathLength {
+		return p.tg.SendMessage(chatID, p.text(userID, msgLongFolderName))
+	}
 
 	ok, err := p.storage.IsFolderExist(ctx, userID, folder)
 	if err != nil {
 		return errhandling.Wrap("can't rename folder", err)
 	}
 	if ok {
-		return p.tg.SendMessage(chatID, msgCantRename)
+		return p.tg.SendMessage(chatID, p.text(userID, msgCantRename))
 	}
 
-	err = p.storage.RenameFolder(ctx, userID, folder, p.sessions[userID].lastMessage)
+	err = p.storage.RenameFolder(ctx, userID, folder, oldFolder)
 	if err != nil {
 		return errhandling.Wrap("can't rename folder", err)
 	}
 
-	return p.tg.SendMessage(chatID, msgFolderRenamed)
+	return p.tg.SendMessage(chatID, p.text(userID, msgFolderRenamed))
 }
 
 func (p *Processor) sendRandom(ctx context.Context, chatID int, userID int) (err error) {
@@ -190,35 +332,118 @@ func (p *Processor) sendRandom(ctx context.Context, chatID int, userID int) (err
 		return err
 	}
 	if errors.Is(err, storage.ErrNoSavedPages) {
-		return p.tg.SendMessage(chatID, msgNoSavedPages)
+		return p.tg.SendMessage(chatID, p.text(userID, msgNoSavedPages))
 	}
 
-	if err := p.tg.SendMessage(chatID, page.URL); err != nil {
+	if err := p.tg.SendKeyboard(chatID, page.URL+plainNote(page), "", p.pageActionsKeyboard(page)); err != nil {
 		return err
 	}
 
-	return p.storage.Remove(ctx, page)
+	// Отправленная ссылка остается в папке и отмечается прочитанной
+	return p.storage.SetStatus(ctx, userID, page.ID, storage.StatusRead)
 }
 
-func (p *Processor) sendHelp(chatID int) error {
-	return p.tg.SendMessage(chatID, msgHelp)
+// sendUnread() sends one page of the unread links or, if messageID isn't 0, shows it in place of that message
+func (p *Processor) sendUnread(ctx context.Context, chatID int, messageID int, userID int, page int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't do command: can't send unread", err) }()
+
+	count, err := p.storage.CountUnreadPages(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return p.show(chatID, messageID, p.text(userID, msgNoUnread), nil)
+	}
+
+	pg := newPagination(page, p.pageSize, count)
+
+	pages, err := p.storage.GetUnread(ctx, userID, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	urls := make([]string, 0, len(pages))
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(pages)+1)
+	for i, page := range pages {
+		urls = append(urls, page.URL+" ("+page.Folder+")"+plainNote(page))
+		num := " " + strconv.Itoa(pg.offset()+i+1)
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{
+			{Text: p.text(userID, btnMarkRead) + num, CallbackData: pageActionData(MarkReadCmd, page.ID)},
+			{Text: p.text(userID, btnArchive) + num, CallbackData: pageActionData(ArchiveCmd, page.ID)},
+		})
+	}
+
+	text := p.text(userID, msgUnreadList) + "\n" + conc.EnumeratedJoinFrom(urls, pg.offset()+1)
+
+	return p.show(chatID, messageID, text, pg.withPages(buttons, unreadPageData))
 }
 
+func (p *Processor) sendHelp(chatID int, userID int) error {
+	lang, _ := p.language(userID)
+
+	return p.tg.SendMessage(chatID, helpText(lang))
+}
+
+// sendRusHelp() sends the help in Russian whatever language the user has chosen
 func (p *Processor) sendRusHelp(chatID int) error {
-	return p.tg.SendMessage(chatID, msgRusHelp)
+	return p.tg.SendMessage(chatID, helpText(i18n.Russian))
+}
+
+func (p *Processor) sendHello(chatID int, userID int) error {
+	lang, _ := p.language(userID)
+
+	return p.tg.SendMessage(chatID, p.text(userID, msgHello)+"\n\n"+helpText(lang))
 }
 
-func (p *Processor) sendHello(chatID int) error {
-	return p.tg.SendMessage(chatID, msgHello)
+// packLinks() keeps links of the message, the post they were forwarded from and the note in the session.
+// Every link takes a line, the source and the note follow the link after tabs.
+// The note is given only for a single link and is already written in one line
+func packLinks(links []string, source string, note string) string {
+	lines := make([]string, 0, len(links))
+
+	for _, link := range links {
+		linkSource := source
+		if linkSource == link {
+			linkSource = ""
+		}
+		lines = append(lines, strings.Join([]string{link, linkSource, note}, fieldsSeparator))
+	}
+
+	return strings.Join(lines, linksSeparator)
 }
 
-func isAddCmd(text string) bool {
-	return isURL(text)
+// unpackLinks() returns links with their sources and notes packed by packLinks()
+func unpackLinks(packed string) (links []string, sources []string, notes []string) {
+	for _, line := range strings.Split(packed, linksSeparator) {
+		fields := strings.SplitN(line, fieldsSeparator, 3)
+		for len(fields) < 3 {
+			fields = append(fields, "")
+		}
+		links = append(links, fields[0])
+		sources = append(sources, fields[1])
+		notes = append(notes, fields[2])
+	}
+
+	return links, sources, notes
 }
 
-func isURL(text string) bool {
-	// Необходим протокол в ссылке (https://)
-	u, err := url.Parse(text)
+// linksToSave() returns links of the message that can be saved, links without
+// a scheme get https. If the message has no entities (e.g. it was sent through the API),
+// the whole text is checked
+func (p *Processor) linksToSave(text string, urls []string) []string {
+	var links []string
+
+	for _, u := range urls {
+		if link, err := p.links.Normalize(u); err == nil {
+			links = append(links, link)
+		}
+	}
+
+	if len(links) == 0 {
+		if link, err := p.links.Normalize(text); err == nil {
+			links = append(links, link)
+		}
+	}
 
-	return err == nil && u.Host != ""
+	return links
 }
@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"strings"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/i18n"
+)
+
+// command describes a user command for the menu next to the input field and for /help
+type command struct {
+	name        string
+	description msgKey
+	scope       string // Чаты, в меню которых показывается команда
+}
+
+// Telegram показывает меню самой узкой области и не добавляет к нему команды по умолчанию,
+// поэтому команды из ScopeDefault входят в меню каждой области
+var menuScopes = []string{tgClient.ScopeDefault, tgClient.ScopeAllPrivateChats, tgClient.ScopeAllGroupChats}
+
+// Команды в том порядке, в котором они показываются в меню и в справке.
+// /start не показывается: Telegram отправляет ее сам при первом запуске бота
+var commands = []command{
+	{CreateFolderCmd, descCreate, tgClient.ScopeDefault},
+	{ShowFolderCmd, descShow, tgClient.ScopeDefault},
+	{ChooseFolderForRenaming, descRename, tgClient.ScopeDefault},
+	{MoveFolderCmd, descMove, tgClient.ScopeDefault},
+	{ChooseLinkForDeletionCmd, descDelete, tgClient.ScopeDefault},
+	{DeleteFolderCmd, descDeleteFolder, tgClient.ScopeDefault},
+	{RndCmd, descRnd, tgClient.ScopeDefault},
+	{UnreadCmd, descUnread, tgClient.ScopeDefault},
+	{ExportCmd, descExport, tgClient.ScopeDefault},
+	{ImportCmd, descImport, tgClient.ScopeDefault},
+	{ShareCmd, descShare, tgClient.ScopeAllPrivateChats}, // Код приглашения не должны видеть все участники группы
+	{JoinCmd, descJoin, tgClient.ScopeAllPrivateChats},
+	{SharedCmd, descShared, tgClient.ScopeDefault},
+	{CaptureCmd, descCapture, tgClient.ScopeAllGroupChats},
+	{RemindCmd, descRemind, tgClient.ScopeDefault},
+	{TimezoneCmd, descTimezone, tgClient.ScopeDefault},
+	{DigestCmd, descDigest, tgClient.ScopeDefault},
+	{DeadCmd, descDead, tgClient.ScopeDefault},
+	{SnapshotsCmd, descSnapshots, tgClient.ScopeDefault},
+	{StatsCmd, descStats, tgClient.ScopeDefault},
+	{LanguageCmd, descLanguage, tgClient.ScopeDefault},
+	{HelpCmd, descHelp, tgClient.ScopeDefault},
+	{RusHelpCmd, descRusHelp, tgClient.ScopeDefault},
+	{CancelCmd, descCancel, tgClient.ScopeDefault},
+}
+
+// RegisterCommands() publishes the command menu in every supported language.
+// The menu in the default language is shown to users whose language isn't supported
+func (p *Processor) RegisterCommands() (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't register commands", err) }()
+
+	for _, scope := range menuScopes {
+		list := menu(scope)
+		if err := p.tg.SetMyCommands(botCommands(list, i18n.Default), scope, ""); err != nil {
+			return err
+		}
+		for _, lang := range i18n.Languages {
+			if err := p.tg.SetMyCommands(botCommands(list, lang), scope, string(lang)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// menu() returns the commands shown in the chats of the scope
+func menu(scope string) []command {
+	var res []command
+	for _, cmd := range commands {
+		if cmd.scope == scope || cmd.scope == tgClient.ScopeDefault {
+			res = append(res, cmd)
+		}
+	}
+
+	return res
+}
+
+func botCommands(list []command, lang i18n.Lang) []tgClient.BotCommand {
+	res := make([]tgClient.BotCommand, 0, len(list))
+	for _, cmd := range list {
+		res = append(res, tgClient.BotCommand{
+			Command:     strings.TrimPrefix(cmd.name, "/"),
+			Description: messages.Text(lang, cmd.description),
+		})
+	}
+
+	return res
+}
+
+// helpText() returns the help with the list of commands from the registry
+func helpText(lang i18n.Lang) string {
+	var sb strings.Builder
+
+	sb.WriteString(messages.Text(lang, msgHelp) + "\n\n")
+	sb.WriteString(messages.Text(lang, msgHelpCommands) + "\n")
+	for _, cmd := range commands {
+		sb.WriteString(cmd.name + " - " + messages.Text(lang, cmd.description) + "\n")
+	}
+	sb.WriteString("\n" + messages.Text(lang, msgHelpFooter))
+
+	return sb.String()
+}
@@ -1,103 +1,232 @@
 package telegram
 
-const msgHelp = `With this bot, you can store your important links and sort them by folders😊
-
-To save the link:
-1. Create a folder using /create
-2. Enter the link (https://example.com)
-3. Select the folder where you want to save the link
-(To save to an existing folder, just enter the link)
+// Ссылки одного сообщения хранятся в сессии одной строкой, поля ссылки разделены табуляцией
+const (
+	linksSeparator  = "\n"
+	fieldsSeparator = "\t"
+)
 
-To view the contents of a folder:
-1. Enter the show command
-2. Select the desired folder
+// Путь папки передается в callback data ("/nav <path>"), которая ограничена 64 байтами
+const maxFolderPathLength = 64 - len(NavigateCmd) - 1
 
-To delete a folder:
-1. Enter the command /delete_folder
-2. Select a folder
-!!! BE CAREFUL !!! this command will delete the folder and all its contents without the possibility of recovery
+// msgKey identifies a message in the catalog, the texts of messages are in messages.go
+type msgKey int
 
-To delete a link:
-1. Enter the command /delete
-2. Select a folder
-3. Select a link
-!!! BE CAREFUL !!! this command will delete the link without the possibility of recovery
+const (
+	// Help
+	msgHelp msgKey = iota
+	msgHelpCommands
+	msgHelpFooter
+	msgHello
 
-To abort the operation, type /cancel
+	// Command descriptions
+	descCreate
+	descShow
+	descRename
+	descMove
+	descDelete
+	descDeleteFolder
+	descRnd
+	descUnread
+	descExport
+	descImport
+	descShare
+	descJoin
+	descShared
+	descCapture
+	descRemind
+	descTimezone
+	descDigest
+	descDead
+	descSnapshots
+	descStats
+	descLanguage
+	descHelp
+	descRusHelp
+	descCancel
 
-Other commands:
-/help - help about the bot
-/help_rus - help in Russian
-/rename - rename folder
-/rnd - output a random link from any folder
+	// Error
+	msgUnknownCommand
+	msgUnexpectedCommand
+	msgFolderNotExists
+	msgNoSavedPages
+	msgNoFolders
+	msgEmptyFolder
+	msgCantRename
+	msgLongFolderName
+	msgNotAllowed
+	msgTooManyRequests
+	msgPageNotFound
+	msgEmptyFolderName
+	msgSlashInName
+	msgCantMove
+	msgFileTooLarge
+	msgCantImport
+	msgAccessDenied
+	msgInviteNotFound
+	msgNoSharedFolders
+	msgGroupsOnly
+	msgAdminsOnly
+	msgLongNote
+	msgFinishOperation
+	msgUnknownTime
+	msgPastTime
+	msgUnknownTimezone
 
-All commands are available in the menu next to the input field.
-Productive work!`
+	// Warning
+	msgFolderAlreadyExists
+	msgAlreadyExists
+	msgAlsoInFolders
+	msgOwnFolder
 
-const msgRusHelp = `С помощью данного бота ты можешь хранить свои важные ссылки и сортировать их по папкам😊
+	// OK
+	msgNewFolderCreated
+	msgSaved
+	msgSavedSeveral
+	msgFolderDeleted
+	msgPageDeleted
+	msgFolderRenamed
+	msgFolderMoved
+	msgOperationCancelled
+	msgMarkedRead
+	msgArchived
+	msgNoUnread
+	msgUnreadList
+	msgExportReady
+	msgExported
+	msgImported
+	msgLanguageChanged
+	msgInviteCreated
+	msgJoined
+	msgMemberJoined
+	msgLinksAdded
+	msgCaptureOn
+	msgCaptureOff
+	msgNoteSaved
+	msgNoteRemoved
+	msgReminderSet
+	msgReminder
+	msgTimezoneChanged
+	msgDigestSaved
+	msgDigestOff
+	msgDigest
+	msgNoDeadLinks
+	msgDeadRemoved
+	msgSnapshotsOn
+	msgSnapshotsOff
+	msgSnapshot
+	msgNoSnapshot
+	msgStats
+	msgStatsFolders
+	msgStatsMore
+	msgStatsWeeks
+	msgStatsDomains
+	msgStatsOldest
+	msgStatsOldestUndated
 
-Чтобы сохранить ссылку:
-1. Создайте папку с помощью /create
-2. Введите ссылку (https://example.com)
-3. Выберите папку, в которую хотите сохранить ссылку
-(Чтобы сохранить в уже существующую папку, просто введите ссылку)
+	// Input Suggestion
+	msgChooseFolder
+	msgChooseLink
+	msgEnterFolderName
+	msgEnterNewFolderName
+	msgChooseNewParent
+	msgChooseFormat
+	msgSendFile
+	msgChooseLanguage
+	msgChooseRole
+	msgJoinUsage
+	msgEnterNote
+	msgCurrentNote
+	msgChooseRemindLink
+	msgEnterRemindTime
+	msgEnterTimezone
+	msgChooseDigestDay
+	msgChooseDigestHour
+	msgChooseDigestFolders
+	msgDeadList
 
-Чтобы посмотреть содержимое папки:
-1. Введите команду /show
-2. Выберите нужную папку
+	// Hints for an unexpected message during the operation
+	msgHintCancel
+	msgHintRename
+	msgHintMove
+	msgHintMoveTo
+	msgHintDeleteLinkFolder
+	msgHintDeleteLink
+	msgHintShow
+	msgHintDeleteFolder
+	msgHintExport
+	msgHintImport
+	msgHintLanguage
+	msgHintShare
+	msgHintShareRole
+	msgHintShared
+	msgHintNote
+	msgHintRemind
+	msgHintRemindLink
+	msgHintRemindTime
+	msgHintTimezone
+	msgHintDigest
+	msgHintDigestHour
+	msgHintDigestFolders
+	msgHintDead
 
-Чтобы удалить папку:
-1. Введите команду /delete_folder
-2. Выберите папку
-!!! БУДЬТЕ ВНИМАТЕЛЬНЫ !!! данная команда удалит папку и все ее содержимое без возможности восстановления
+	// Buttons
+	btnMarkRead
+	btnArchive
+	btnRootFolder
+	btnOpenFolder
+	btnNetscape
+	btnRoleViewer
+	btnRoleContributor
+	btnRoleOwner
+	btnAddNote
+	btnEditNote
+	btnRemind
+	btnSnapshot
+	btnEveryDay
+	btnDone
+	btnPurge
 
-Чтобы удалить ссылку:
-1. Введите команду /delete
-2. Выберите папку и все ее содержимое 
-3. Выберите ссылку
-!!! БУДЬТЕ ВНИМАТЕЛЬНЫ !!! данная команда удалит ссылку без возможности восстановления
+	// Role names
+	roleViewer
+	roleContributor
+	roleOwner
 
-Чтобы прервать операцию, введите /cancel
+	// Digest schedule
+	digestDaily
+	digestWeekly
+	digestAllFolders
+	digestFolders
+	digestNotSet
 
-Прочие команды:
-/help - справка о боте
-/help_rus - Справка на русском
-/rename - переименование папки
-/rnd - вывод случайной ссылки из любой папки
+	// Link check
+	deadUnreachable
 
-Все команды доступны в меню рядом с полем ввода.
-Продуктивной работы!`
+	// Weekdays, in the order of time.Weekday
+	weekdaySunday
+	weekdayMonday
+	weekdayTuesday
+	weekdayWednesday
+	weekdayThursday
+	weekdayFriday
+	weekdaySaturday
 
-const msgHello = "Hi there!\n\n" + msgHelp
+	msgOriginalPost
+)
 
+// Buttons that look the same in all languages
 const (
-	// Error
-	msgUnknownCommand    = "Unknown command 🤔"
-	msgUnexpectedCommand = "Unexpected command 🤕"
-	msgFolderNotExists   = "This folder doesn't exist 🥺"
-	msgNoSavedPages      = "You have no saved pages 😢"
-	msgNoFolders         = "No existing folders 😢"
-	msgEmptyFolder       = "This folder is still empty 😢"
-	msgCantRename        = "Cannot be renamed. A folder with this name already exists 😧"
-	msgLongMessage       = "The message is too long, enter something shorter 🥴"
-
-	// Warning
-	msgFolderAlreadyExists = "This folder already exists 😌"
-	msgAlreadyExists       = "You already have this page in your list 😌"
-
-	// OK
-	msgNewFolderCreated   = "New Folder created 😇"
-	msgSaved              = "Saved! 👌"
-	msgFolderDeleted      = "Folder deleted 🫡"
-	msgPageDeleted        = "Link deleted 🫡"
-	msgFolderRenamed      = "Folder renamed 👌"
-	msgOperationCancelled = "Operation cancelled 🤓"
-
-	// Input Suggestion
-	msgChooseFolder       = "Choose folder"
-	msgChooseLink         = "Choose link for deletion"
-	msgEnterFolderName    = "Enter the folder name"
-	msgEnterNewFolderName = "Enter new folder name"
+	btnRoot       = "🏠"
+	btnSubfolders = "📁 "
+	btnShared     = "👥 "
+	noteMark      = "📝 "  // Отмечает заметку под ссылкой
+	checkMark     = "✅ "  // Отмечает выбранный вариант в списке с несколькими вариантами
+	deadMark      = "⚠️ " // Отмечает ссылку, которая не открылась при последней проверке
+	btnDelete     = "🗑 "
+	snapshotMark  = "📄 "
+	btnJSON       = "JSON"
+	btnCSV        = "CSV"
+	btnMarkdown   = "Markdown"
 )
 
 // User commands
@@ -110,16 +239,57 @@ const (
 	ChooseLinkForDeletionCmd = "/delete" // Удаляет ссылку из нужной папки
 	SaveLinkCmd              = "/save"   // Сохраняет ссылку 2
 	//ChangeFolderCmd = "/change"      // Меняет местонахождение ссылки
-	RndCmd = "/rnd" // Скидывает случайную ссылку
+	RndCmd    = "/rnd"    // Скидывает случайную ссылку
+	UnreadCmd = "/unread" // Показывает непрочитанные ссылки
+	ExportCmd = "/export" // Выгружает все ссылки файлом
+	ImportCmd = "/import" // Загружает ссылки из файла
+
+	LanguageCmd = "/language" // Меняет язык бота
+
+	ShareCmd  = "/share"  // Создает приглашение в папку
+	JoinCmd   = "/join"   // Открывает доступ к папке по приглашению, "/join <code>"
+	SharedCmd = "/shared" // Показывает папки, к которым открыт доступ
+
+	CaptureCmd = "/capture" // Включает и выключает сохранение ссылок из сообщений группы
+
+	RemindCmd   = "/remind"   // Напоминает о ссылке в выбранное время
+	TimezoneCmd = "/timezone" // Меняет часовой пояс, в котором вводится время напоминаний
+	DigestCmd   = "/digest"   // Настраивает регулярную подборку ссылок, "/digest off" выключает ее
+	DeadCmd     = "/dead"     // Показывает битые ссылки
+
+	SnapshotsCmd = "/snapshots" // Включает и выключает сохранение копий страниц
+
+	StatsCmd = "/stats" // Показывает статистику ссылок
 
 	ShowFolderCmd           = "/show"          // Показывает содержимое папки 3
 	CreateFolderCmd         = "/create"        // Создает новую папку 1
 	DeleteFolderCmd         = "/delete_folder" // Удаляет папку
 	ChooseFolderForRenaming = "/rename"        // Изменяет название папки
+	MoveFolderCmd           = "/move"          // Перемещает папку в другую папку
 )
 
 // Internal commands
 const (
-	DeleteLinkCmd   = "/delete_link"
-	RenameFolderCmd = "/rename_folder"
+	DeleteLinkCmd    = "/delete_link"
+	RenameFolderCmd  = "/rename_folder"
+	MarkReadCmd      = "/mark_read" // Кнопка под ссылкой, "/mark_read <id>"
+	ArchiveCmd       = "/archive"   // Кнопка под ссылкой, "/archive <id>"
+	NoteCmd          = "/note"      // Кнопка под ссылкой, "/note <id>"
+	MoveFolderToCmd  = "/move_to"
+	NavigateCmd      = "/nav"       // Переход по дереву папок, "/nav <path>"
+	RootFolderCmd    = "/root"      // Корень дерева папок при перемещении
+	PageCmd          = "/page"      // Страница списка текущей операции, "/page <n>"
+	ShowPageCmd      = "/show_page" // Страница содержимого папки, "/show_page <folderID> <n>"
+	ShareRoleCmd     = "/share_role"
+	SharedPageCmd    = "/shared_page" // Страница содержимого общей папки, "/shared_page <id> <n>"
+	RemindLinkCmd    = "/remind_link"
+	RemindTimeCmd    = "/remind_time" // Кнопка под ссылкой, "/remind_time <id>"
+	SnapshotCmd      = "/snapshot"    // Кнопка под ссылкой, "/snapshot <id>"
+	DigestHourCmd    = "/digest_hour"
+	DigestFoldersCmd = "/digest_folders"
+	ToggleCmd        = "/toggle"         // Выбор варианта в списке с несколькими вариантами, "/toggle <id> <n>"
+	DoneCmd          = "/done"           // Завершает выбор нескольких вариантов
+	PurgeCmd         = "/purge"          // Удаляет все битые ссылки
+	PageIndicatorCmd = "/page_indicator" // Номер текущей страницы, нажатие ничего не меняет
+	UnreadPageCmd    = "/unread_page"    // Страница списка непрочитанных ссылок, "/unread_page <n>"
 )
@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	conc "github.com/hahaclassic/golang-telegram-bot.git/lib/concatenation"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/linkcheck"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const (
+	recheckInterval    = 7 * 24 * time.Hour // Как часто перепроверяется одна ссылка
+	checkBatchSize     = 100                // Ссылок за один запуск проверки
+	checkChunkSize     = 16                 // Ссылок, результаты которых сохраняются вместе
+	checkRunTime       = 2 * time.Minute    // После этого новые ссылки ждут следующего запуска
+	deleteButtonsInRow = 5
+)
+
+// isDead() tells whether the link is broken: several checks in a row have failed.
+// A single failure may be a network hiccup, such links aren't marked
+func isDead(page *storage.Page) bool {
+	return page.CheckFailures >= storage.DeadAfterFailures
+}
+
+// CheckLinks() requests the links that haven't been checked for a while and records the results.
+// It is run by the scheduler, every run checks a batch of links, the oldest checked first.
+// Links are checked in chunks until checkRunTime is over, the rest wait for the next run
+func (p *Processor) CheckLinks(ctx context.Context, now time.Time) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't check links", err) }()
+
+	pages, err := p.storage.GetPagesToCheck(ctx, now.Add(-recheckInterval), checkBatchSize)
+	if err != nil {
+		return err
+	}
+
+	deadline := p.clock.Now().Add(checkRunTime)
+
+	for len(pages) > 0 && p.clock.Now().Before(deadline) {
+		n := checkChunkSize
+		if n > len(pages) {
+			n = len(pages)
+		}
+		if err := p.checkPages(ctx, pages[:n], now); err != nil {
+			return err
+		}
+		pages = pages[n:]
+	}
+
+	return nil
+}
+
+// checkPages() requests the links at the same time and records the results
+func (p *Processor) checkPages(ctx context.Context, pages []*storage.Page, now time.Time) error {
+	urls := make([]string, 0, len(pages))
+	for _, page := range pages {
+		urls = append(urls, page.URL)
+	}
+
+	statuses := p.checker.Check(ctx, urls)
+	if err := ctx.Err(); err != nil {
+		// Прерванные запросы выглядят как недоступные сайты, такие результаты не сохраняются
+		return err
+	}
+
+	for _, page := range pages {
+		status := statuses[page.URL]
+		if err := p.storage.SetCheckResult(ctx, page.ID, status, linkcheck.IsDead(status), now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendDeadLinks() shows one page of the broken links with buttons that delete them
+// and buttons that open the saved copies
+func (p *Processor) sendDeadLinks(ctx context.Context, chatID int, messageID int, userID int, page int) (err error) {
+	defer func() {
+		if err != ErrEmptyFolder {
+			err = errhandling.WrapIfErr("can't send dead links", err)
+		}
+	}()
+
+	count, err := p.storage.CountDeadPages(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		_ = p.show(chatID, messageID, p.text(userID, msgNoDeadLinks), nil)
+		return ErrEmptyFolder
+	}
+
+	pg := newPagination(page, p.pageSize, count)
+
+	pages, err := p.storage.GetDeadPages(ctx, userID, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	links := make([]string, 0, len(pages))
+	buttons := [][]tgClient.InlineKeyboardButton{}
+	var row, copies []tgClient.InlineKeyboardButton
+
+	for i, link := range pages {
+		status := format.Escape(" (" + link.Folder + ", " + p.checkStatus(userID, link) + ")")
+		links = append(links, format.Link(linkTitle(link.URL), link.URL)+status)
+
+		row = append(row, tgClient.InlineKeyboardButton{
+			Text:         btnDelete + strconv.Itoa(pg.offset()+i+1),
+			CallbackData: toggleData(link.ID, pg.current),
+		})
+		if len(row) == deleteButtonsInRow || i == len(pages)-1 {
+			buttons = append(buttons, row)
+			row = nil
+		}
+
+		// Копию пропавшей страницы можно открыть прямо из списка
+		if link.Snapshot {
+			copies = append(copies, tgClient.InlineKeyboardButton{
+				Text:         snapshotMark + strconv.Itoa(pg.offset()+i+1),
+				CallbackData: pageActionData(SnapshotCmd, link.ID),
+			})
+		}
+	}
+
+	for len(copies) > 0 {
+		n := len(copies)
+		if n > deleteButtonsInRow {
+			n = deleteButtonsInRow
+		}
+		buttons = append(buttons, copies[:n])
+		copies = copies[n:]
+	}
+
+	buttons = pg.withPages(buttons, pageData)
+	buttons = append(buttons, []tgClient.InlineKeyboardButton{
+		{Text: p.text(userID, btnPurge), CallbackData: PurgeCmd},
+		{Text: p.text(userID, btnDone), CallbackData: DoneCmd},
+	})
+
+	items := append([]string{p.text(userID, msgDeadList, count) + "\n"}, conc.Enumerate(links, pg.offset()+1)...)
+
+	return p.showFormatted(chatID, messageID, strings.Join(items, ""), format.ParseMode(), buttons)
+}
+
+// checkStatus() describes the result of the last check: the status code or that the site is down
+func (p *Processor) checkStatus(userID int, page *storage.Page) string {
+	if page.HTTPStatus == linkcheck.StatusUnreachable {
+		return p.text(userID, deadUnreachable)
+	}
+
+	return strconv.Itoa(page.HTTPStatus)
+}
+
+// deleteDeadLink() deletes one broken link and shows the rest of the list
+func (p *Processor) deleteDeadLink(ctx context.Context, meta *CallbackMeta, id int, page int) error {
+	link, err := p.storage.GetPage(ctx, meta.UserID, id)
+	if err != nil && !errors.Is(err, storage.ErrPageNotFound) {
+		return errhandling.Wrap("can't delete dead link", err)
+	}
+
+	if err == nil {
+		if err := p.storage.Remove(ctx, link); err != nil {
+			return errhandling.Wrap("can't delete dead link", err)
+		}
+	}
+
+	return p.sendDeadLinks(ctx, meta.ChatID, meta.MessageID, meta.UserID, page)
+}
+
+// purgeDeadLinks() deletes all broken links of the user
+func (p *Processor) purgeDeadLinks(ctx context.Context, meta *CallbackMeta) error {
+	n, err := p.storage.RemoveDeadPages(ctx, meta.UserID)
+	if err != nil {
+		return errhandling.Wrap("can't purge dead links", err)
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgDeadRemoved, n), nil)
+}
@@ -0,0 +1,365 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	conc "github.com/hahaclassic/golang-telegram-bot.git/lib/concatenation"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const (
+	digestSize = 5 // Ссылок в одной подборке
+
+	// Пауза между подборками разных пользователей. Telegram ограничивает рассылку
+	// примерно 30 сообщениями в секунду, подборки не должны мешать ответам на команды
+	digestSendInterval = 100 * time.Millisecond
+
+	digestEveryDay = "daily" // Кнопка ежедневной подборки, остальные кнопки - номера дней недели
+	digestOff      = "off"   // "/digest off"
+)
+
+var weekdayNames = [7]msgKey{
+	weekdaySunday, weekdayMonday, weekdayTuesday, weekdayWednesday, weekdayThursday, weekdayFriday, weekdaySaturday,
+}
+
+// digestDaysKeyboard() returns the button of the daily digest and one button for each weekday
+func (p *Processor) digestDaysKeyboard(userID int) [][]tgClient.InlineKeyboardButton {
+	buttons := [][]tgClient.InlineKeyboardButton{{{Text: p.text(userID, btnEveryDay), CallbackData: digestEveryDay}}}
+
+	// Неделя начинается с понедельника
+	var row []tgClient.InlineKeyboardButton
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		row = append(row, tgClient.InlineKeyboardButton{Text: p.text(userID, weekdayNames[day]), CallbackData: strconv.Itoa(int(day))})
+		if len(row) == 4 || i == 7 {
+			buttons = append(buttons, row)
+			row = nil
+		}
+	}
+
+	return buttons
+}
+
+// digestHoursKeyboard() returns 24 buttons of hours in 4 rows
+func digestHoursKeyboard() [][]tgClient.InlineKeyboardButton {
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, 4)
+
+	for hour := 0; hour < 24; hour++ {
+		if hour%6 == 0 {
+			buttons = append(buttons, nil)
+		}
+		row := &buttons[len(buttons)-1]
+		*row = append(*row, tgClient.InlineKeyboardButton{Text: clockHour(hour), CallbackData: strconv.Itoa(hour)})
+	}
+
+	return buttons
+}
+
+func clockHour(hour int) string {
+	return fmt.Sprintf("%02d:00", hour)
+}
+
+func toggleData(id int, page int) string {
+	return ToggleCmd + " " + strconv.Itoa(id) + " " + strconv.Itoa(page)
+}
+
+// toggled() parses callback data of the buttons in the list with several choices
+func toggled(data string) (id int, page int, ok bool) {
+	return listPage(data, ToggleCmd)
+}
+
+// digestSummary() describes the schedule and the folders of the digest
+func (p *Processor) digestSummary(userID int, d *storage.Digest) string {
+	var schedule string
+
+	switch d.Period {
+	case storage.DigestDaily:
+		schedule = p.text(userID, digestDaily, clockHour(d.Hour))
+	case storage.DigestWeekly:
+		schedule = p.text(userID, digestWeekly, p.text(userID, weekdayNames[d.Weekday]), clockHour(d.Hour))
+	default:
+		return p.text(userID, digestNotSet)
+	}
+
+	if len(d.Folders) == 0 {
+		return schedule + ", " + p.text(userID, digestAllFolders)
+	}
+
+	return schedule + ", " + p.text(userID, digestFolders, strings.Join(d.Folders, ", "))
+}
+
+// askDigestDay() shows the current digest and asks when to send it
+func (p *Processor) askDigestDay(ctx context.Context, r *request) error {
+	d, err := p.storage.GetDigest(ctx, r.userID)
+	if err != nil {
+		return errhandling.Wrap("can't ask digest day", err)
+	}
+
+	return p.show(r.chatID, 0, p.text(r.userID, msgChooseDigestDay, p.digestSummary(r.userID, d)), p.digestDaysKeyboard(r.userID))
+}
+
+func (p *Processor) chooseDigestHour(ctx context.Context, meta *CallbackMeta) error {
+	loc, err := p.location(ctx, meta.UserID)
+	if err != nil {
+		return errhandling.Wrap("can't choose digest hour", err)
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgChooseDigestHour, loc.String()), digestHoursKeyboard())
+}
+
+// setDigestSchedule() saves the day and the hour chosen on the previous steps and offers to choose folders.
+// The digest is counted as sent now, so the first one comes at the chosen time, not right away
+func (p *Processor) setDigestSchedule(ctx context.Context, meta *CallbackMeta, day string, rawHour string) (err error) {
+	defer func() {
+		if err != ErrNoFolders {
+			err = errhandling.WrapIfErr("can't set digest schedule", err)
+		}
+	}()
+
+	hour, err := strconv.Atoi(rawHour)
+	if err != nil || hour < 0 || hour > 23 {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+	}
+
+	d, err := p.storage.GetDigest(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	d.Period, d.Hour, d.Sent = storage.DigestDaily, hour, p.clock.Now()
+	if day != digestEveryDay {
+		weekday, err := strconv.Atoi(day)
+		if err != nil || weekday < 0 || weekday > 6 {
+			return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+		}
+		d.Period, d.Weekday = storage.DigestWeekly, time.Weekday(weekday)
+	}
+
+	if err := p.storage.SetDigest(ctx, d); err != nil {
+		return err
+	}
+
+	return p.chooseDigestFolders(ctx, meta, 0)
+}
+
+// chooseDigestFolders() shows the folders of the user, the ones chosen for the digest are marked.
+// Without folders there is nothing to choose and the operation is finished
+func (p *Processor) chooseDigestFolders(ctx context.Context, meta *CallbackMeta, page int) (err error) {
+	defer func() {
+		if err != ErrNoFolders {
+			err = errhandling.WrapIfErr("can't choose digest folders", err)
+		}
+	}()
+
+	d, err := p.storage.GetDigest(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	count, err := p.storage.CountFolders(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		_ = p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgDigestSaved, p.digestSummary(meta.UserID, d)), nil)
+		return ErrNoFolders
+	}
+
+	pg := newPagination(page, p.pageSize, count)
+
+	folders, err := p.storage.GetFoldersPage(ctx, meta.UserID, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	// Путь папки может не поместиться в callback data вместе с командой, поэтому передается id
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(folders)+2)
+	for _, folder := range folders {
+		id, err := p.storage.GetFolderID(ctx, meta.UserID, folder)
+		if err != nil {
+			return err
+		}
+
+		text := folder
+		if contains(d.Folders, folder) {
+			text = checkMark + folder
+		}
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: text, CallbackData: toggleData(id, pg.current)}})
+	}
+
+	buttons = pg.withPages(buttons, pageData)
+	buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: p.text(meta.UserID, btnDone), CallbackData: DoneCmd}})
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgChooseDigestFolders), buttons)
+}
+
+// toggleDigestFolder() adds the folder to the digest or removes it
+func (p *Processor) toggleDigestFolder(ctx context.Context, meta *CallbackMeta, id int, page int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't toggle digest folder", err) }()
+
+	folder, err := p.storage.GetFolderByID(ctx, meta.UserID, id)
+	if errors.Is(err, storage.ErrFolderNotFound) {
+		return p.chooseDigestFolders(ctx, meta, page)
+	}
+	if err != nil {
+		return err
+	}
+
+	d, err := p.storage.GetDigest(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	folders := make([]string, 0, len(d.Folders)+1)
+	for _, chosen := range d.Folders {
+		if chosen != folder {
+			folders = append(folders, chosen)
+		}
+	}
+	if len(folders) == len(d.Folders) {
+		folders = append(folders, folder)
+	}
+
+	if err := p.storage.SetDigestFolders(ctx, meta.UserID, folders); err != nil {
+		return err
+	}
+
+	return p.chooseDigestFolders(ctx, meta, page)
+}
+
+// finishDigest() shows the saved digest settings
+func (p *Processor) finishDigest(ctx context.Context, meta *CallbackMeta) error {
+	d, err := p.storage.GetDigest(ctx, meta.UserID)
+	if err != nil {
+		return errhandling.Wrap("can't finish digest", err)
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgDigestSaved, p.digestSummary(meta.UserID, d)), nil)
+}
+
+// disableDigest() turns the digest off, the chosen folders are kept for the next time
+func (p *Processor) disableDigest(ctx context.Context, r *request) error {
+	d, err := p.storage.GetDigest(ctx, r.userID)
+	if err != nil {
+		return errhandling.Wrap("can't disable digest", err)
+	}
+
+	d.Period = storage.DigestOff
+	if err := p.storage.SetDigest(ctx, d); err != nil {
+		return errhandling.Wrap("can't disable digest", err)
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgDigestOff))
+}
+
+// SendDigests() sends the digests whose time has come. It is run by the scheduler.
+// A digest missed while the bot was stopped is sent once after the restart.
+// Digests are sent one by one with a pause, so a large mailing doesn't hit the limits of Telegram
+func (p *Processor) SendDigests(ctx context.Context, now time.Time) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't send digests", err) }()
+
+	digests, err := p.storage.GetDigests(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+
+	for _, d := range digests {
+		loc, err := p.location(ctx, d.UserID)
+		if err != nil {
+			return err
+		}
+		if !digestSlot(d, now.In(loc)).After(d.Sent) {
+			continue
+		}
+
+		if sent > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.clock.After(digestSendInterval):
+			}
+		}
+
+		if err := p.sendDigest(ctx, d.UserID); err != nil {
+			return err
+		}
+		sent++
+
+		if err := p.storage.SetDigestSent(ctx, d.UserID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// digestSlot() returns the last time the digest was due, now is in the time zone of the user
+func digestSlot(d storage.Digest, now time.Time) time.Time {
+	slot := time.Date(now.Year(), now.Month(), now.Day(), d.Hour, 0, 0, 0, now.Location())
+
+	days := 1
+	if d.Period == storage.DigestWeekly {
+		days = 7
+		slot = slot.AddDate(0, 0, -((int(now.Weekday()) - int(d.Weekday) + 7) % 7))
+	}
+
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -days)
+	}
+
+	return slot
+}
+
+// sendDigest() sends a handful of links with buttons to mark them read. Users without links get nothing.
+// A digest that can't be delivered (e.g. the user blocked the bot) is only logged
+func (p *Processor) sendDigest(ctx context.Context, userID int) error {
+	pages, err := p.storage.GetDigestPages(ctx, userID, digestSize)
+	if err != nil || len(pages) == 0 {
+		return err
+	}
+
+	if err := p.loadLanguage(ctx, userID, ""); err != nil {
+		return err
+	}
+
+	urls := make([]string, 0, len(pages))
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(pages))
+	for i, page := range pages {
+		urls = append(urls, page.URL+" ("+page.Folder+")"+plainNote(page))
+		if page.Status != storage.StatusUnread {
+			continue
+		}
+		num := " " + strconv.Itoa(i+1)
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{
+			{Text: p.text(userID, btnMarkRead) + num, CallbackData: pageActionData(MarkReadCmd, page.ID)},
+			{Text: p.text(userID, btnArchive) + num, CallbackData: pageActionData(ArchiveCmd, page.ID)},
+		})
+	}
+
+	// В личном чате id чата совпадает с id пользователя
+	if err := p.show(userID, 0, p.text(userID, msgDigest)+"\n"+conc.EnumeratedJoin(urls), buttons); err != nil {
+		log.Printf("can't send digest to '%d': %s", userID, err)
+	}
+
+	return nil
+}
+
+func contains(list []string, elem string) bool {
+	for _, existing := range list {
+		if existing == elem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/bookmarks"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const exportFileName = "links"
+
+var statusNames = map[storage.Status]string{
+	storage.StatusUnread:   "unread",
+	storage.StatusRead:     "read",
+	storage.StatusArchived: "archived",
+}
+
+// formatsKeyboard() returns one button for each export format
+func (p *Processor) formatsKeyboard(userID int) [][]tgClient.InlineKeyboardButton {
+	row := make([]tgClient.InlineKeyboardButton, 0, len(bookmarks.Formats))
+	for _, format := range bookmarks.Formats {
+		name, ok := formatNames[format]
+		if !ok {
+			name = p.text(userID, btnNetscape)
+		}
+		row = append(row, tgClient.InlineKeyboardButton{Text: name, CallbackData: string(format)})
+	}
+
+	return [][]tgClient.InlineKeyboardButton{row}
+}
+
+// Названия форматов, которые не нужно переводить
+var formatNames = map[bookmarks.Format]string{
+	bookmarks.JSON:     btnJSON,
+	bookmarks.CSV:      btnCSV,
+	bookmarks.Markdown: btnMarkdown,
+}
+
+// exportLinks() sends all folders and links of the user as a file in the chosen format
+func (p *Processor) exportLinks(ctx context.Context, meta *CallbackMeta, format string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't export links", err) }()
+
+	if !isExportFormat(format) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+	}
+
+	folders, err := p.storage.GetListOfFolders(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	pages, err := p.storage.GetAllPages(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	if len(folders) == 0 && len(pages) == 0 {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgNoSavedPages), nil)
+	}
+
+	collection := bookmarks.Collection{Folders: folders}
+	for _, page := range pages {
+		collection.Bookmarks = append(collection.Bookmarks, bookmarks.Bookmark{
+			URL:     page.URL,
+			Folder:  page.Folder,
+			Status:  statusNames[page.Status],
+			Source:  page.Source,
+			AddedAt: page.Created,
+			Note:    page.Note,
+		})
+	}
+
+	var file bytes.Buffer
+
+	if err := bookmarks.Export(&file, collection, bookmarks.Format(format)); err != nil {
+		return err
+	}
+
+	// Клавиатура выбора формата больше не нужна
+	_ = p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgExportReady), nil)
+
+	return p.tg.SendDocument(meta.ChatID, exportFileName+"."+format, file.Bytes(),
+		p.text(meta.UserID, msgExported, len(pages), len(folders)))
+}
+
+func isExportFormat(format string) bool {
+	for _, f := range bookmarks.Formats {
+		if string(f) == format {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/markup"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Разметка, в которой бот оформляет списки ссылок
+var format = markup.HTML
+
+const maxTitleLength = 60
+
+// folderHeader() returns the bold folder name for the top of the folder contents
+func folderHeader(folder string) string {
+	return format.Bold(folder) + ":\n"
+}
+
+// formatPage() returns the link with a short readable title instead of the full URL,
+// the link to the post it was forwarded from and the note on the next line.
+// Links found broken by the last check are marked
+func formatPage(page *storage.Page, originalPost string) string {
+	res := format.Link(linkTitle(page.URL), page.URL)
+	if isDead(page) {
+		res = deadMark + res
+	}
+	if page.Source != "" {
+		res += " (" + format.Link(originalPost, page.Source) + ")"
+	}
+	if page.Note != "" {
+		res += "\n" + noteMark + format.Italic(page.Note)
+	}
+
+	return res
+}
+
+// plainNote() returns the note line for messages without markup, "" if there is no note
+func plainNote(page *storage.Page) string {
+	if page.Note == "" {
+		return ""
+	}
+
+	return "\n" + noteMark + page.Note
+}
+
+// linkTitle() makes a title from the URL: the host without "www." and the path,
+// cut to maxTitleLength characters
+func linkTitle(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return shorten(rawURL)
+	}
+
+	title := strings.TrimPrefix(u.Hostname(), "www.") + strings.TrimSuffix(u.EscapedPath(), "/")
+	if unescaped, err := url.PathUnescape(title); err == nil {
+		title = unescaped
+	}
+
+	return shorten(title)
+}
+
+func shorten(text string) string {
+	if utf8.RuneCountInString(text) <= maxTitleLength {
+		return text
+	}
+
+	return string([]rune(text)[:maxTitleLength-1]) + "…"
+}
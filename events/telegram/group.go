@@ -0,0 +1,199 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
+)
+
+// Папка, в которую сохраняются ссылки из сообщений группы
+const captureFolder = "Captured"
+
+// Операции, которые удаляют или меняют общие ссылки группы. В группах их могут выполнять
+// только администраторы. Шаги операций указаны отдельно, чтобы нельзя было нажать кнопку,
+// выведенную для администратора
+var adminOperations = map[string]bool{
+	ChooseLinkForDeletionCmd: true,
+	DeleteLinkCmd:            true,
+	DeleteFolderCmd:          true,
+	ChooseFolderForRenaming:  true,
+	RenameFolderCmd:          true,
+	MoveFolderCmd:            true,
+	MoveFolderToCmd:          true,
+	ImportCmd:                true,
+	CaptureCmd:               true,
+	SnapshotsCmd:             true,
+	TimezoneCmd:              true,
+	DigestCmd:                true,
+	DigestHourCmd:            true,
+	DigestFoldersCmd:         true,
+	DeadCmd:                  true,
+}
+
+// Identify() asks Telegram for the username of the bot. Commands in groups are handled
+// only if they are addressed to the bot: /show@username
+func (p *Processor) Identify() error {
+	me, err := p.tg.GetMe()
+	if err != nil {
+		return errhandling.Wrap("can't identify bot", err)
+	}
+	p.username = me.Username
+
+	return nil
+}
+
+// addressed() tells whether the group message is meant for the bot: a command with the username
+// of the bot or the input that the operation started by the sender is waiting for.
+// During the operation the sender can also type commands without the username, e.g. /cancel.
+// The username is cut from the command, so the command is routed as in the private chat
+func (p *Processor) addressed(r *request) bool {
+	state, _ := p.states.State(r.session())
+
+	if !strings.HasPrefix(r.text, "/") {
+		return state != stateIdle && p.states.Can(r.session(), r.input)
+	}
+
+	cmd, args, _ := strings.Cut(r.text, " ")
+	name, bot, ok := strings.Cut(cmd, "@")
+	if !ok {
+		return state != stateIdle
+	}
+	if p.username == "" || !strings.EqualFold(bot, p.username) {
+		return false
+	}
+	r.text = strings.TrimSpace(name + " " + args)
+
+	return true
+}
+
+// capture() silently saves links of the group message if the group has turned it on with /capture
+func (p *Processor) capture(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't capture links", err) }()
+
+	links := p.messageLinks(r.text, r.message)
+	if len(links) == 0 {
+		return nil
+	}
+
+	enabled, err := p.storage.IsCaptureEnabled(ctx, r.userID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	ok, err := p.storage.IsFolderExist(ctx, r.userID, captureFolder)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := p.storage.NewFolder(ctx, r.userID, captureFolder); err != nil {
+			return err
+		}
+	}
+
+	for _, link := range links {
+		if canonical, err := urlnorm.Canonical(link); err == nil {
+			link = canonical
+		}
+
+		page := p.storage.NewPage(link, r.userID, captureFolder)
+		if link != r.message.Source {
+			page.Source = r.message.Source
+		}
+
+		isExists, err := p.storage.IsExist(ctx, page)
+		if err != nil {
+			return err
+		}
+		if isExists {
+			continue
+		}
+
+		if err := p.storage.Save(ctx, page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toggleCapture() turns on or off saving of all links posted in the group
+func (p *Processor) toggleCapture(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't toggle capture", err) }()
+
+	if !r.group {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgGroupsOnly))
+	}
+
+	enabled, err := p.storage.IsCaptureEnabled(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+
+	if err := p.storage.SetCapture(ctx, r.userID, !enabled); err != nil {
+		return err
+	}
+
+	if enabled {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgCaptureOff))
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgCaptureOn, captureFolder))
+}
+
+// adminOnly() lets only administrators of the group run operations that delete or change links.
+// Buttons are checked by the operation of the member who pressed them, buttons under links and
+// pages of folders are available to everyone
+func (p *Processor) adminOnly(route string, next handler) handler {
+	return func(ctx context.Context, r *request) error {
+		if !r.group {
+			return next(ctx, r)
+		}
+
+		operation := route
+		if r.callback != nil {
+			if !operationButton(r.text) {
+				return next(ctx, r)
+			}
+			state, _ := p.states.State(r.session())
+			operation = string(state)
+		}
+		if !adminOperations[operation] {
+			return next(ctx, r)
+		}
+
+		member, err := p.tg.GetChatMember(r.chatID, r.senderID)
+		if err != nil {
+			return err
+		}
+		if member.IsAdmin() {
+			return next(ctx, r)
+		}
+
+		metricDenied.Add("not_admin", 1)
+
+		return p.deny(r, msgAdminsOnly)
+	}
+}
+
+// operationButton() tells whether the button belongs to the current operation
+func operationButton(data string) bool {
+	if data == PageIndicatorCmd {
+		return false
+	}
+	if _, _, ok := pageAction(data); ok {
+		return false
+	}
+	if _, _, ok := folderPage(data); ok {
+		return false
+	}
+	if _, _, ok := sharedPage(data); ok {
+		return false
+	}
+	if _, ok := unreadPage(data); ok {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/bookmarks"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Ссылки без папки (например, с панели закладок браузера) и из папок со слишком длинным путем попадают сюда
+const importFolder = "Imported"
+
+// importLinks() downloads the file sent by the user and saves its folders and links.
+// Links are compared in the canonical form, the ones that are already in the folder are skipped
+func (p *Processor) importLinks(ctx context.Context, chatID int, userID int, document *tgClient.Document) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't import links", err) }()
+
+	if document.FileSize > tgClient.MaxDownloadSize {
+		return p.tg.SendMessage(chatID, p.text(userID, msgFileTooLarge))
+	}
+
+	data, err := p.tg.DownloadFile(document.FileID, tgClient.MaxDownloadSize)
+	if errors.Is(err, tgClient.ErrFileTooLarge) {
+		return p.tg.SendMessage(chatID, p.text(userID, msgFileTooLarge))
+	}
+	if err != nil {
+		return err
+	}
+
+	collection, err := bookmarks.Import(data)
+	if err != nil {
+		return p.tg.SendMessage(chatID, p.text(userID, msgCantImport))
+	}
+
+	existing, err := p.storage.GetListOfFolders(ctx, userID)
+	if err != nil {
+		return err
+	}
+	folders := make(map[string]bool, len(existing))
+	for _, folder := range existing {
+		folders[folder] = true
+	}
+
+	createdFolders := 0
+	createFolder := func(folder string) error {
+		if folders[folder] {
+			return nil
+		}
+		// Родительские папки создаются вместе с вложенной
+		for _, path := range folderpath.Ancestors(folder) {
+			if !folders[path] {
+				folders[path] = true
+				createdFolders++
+			}
+		}
+		return p.storage.NewFolder(ctx, userID, folder)
+	}
+
+	for _, folder := range collection.Folders {
+		if folder = folderpath.Clean(folder); folder != "" && len(folder) <= maxFolderPathLength {
+			if err := createFolder(folder); err != nil {
+				return err
+			}
+		}
+	}
+
+	saved, duplicates, invalid := 0, 0, 0
+
+	for _, b := range collection.Bookmarks {
+		link, err := p.links.Normalize(b.URL)
+		if err != nil {
+			invalid++
+			continue
+		}
+		if canonical, err := urlnorm.Canonical(link); err == nil {
+			link = canonical
+		}
+
+		folder := folderpath.Clean(b.Folder)
+		if folder == "" || len(folder) > maxFolderPathLength {
+			folder = importFolder
+		}
+
+		page := p.storage.NewPage(link, userID, folder)
+		page.Status = statusByName(b.Status)
+		page.Source = b.Source
+		page.Created = b.AddedAt
+		page.Note = cutNote(strings.Join(strings.Fields(b.Note), " "))
+
+		isExists, err := p.storage.IsExist(ctx, page)
+		if err != nil {
+			return err
+		}
+		if isExists {
+			duplicates++
+			continue
+		}
+
+		if err := createFolder(folder); err != nil {
+			return err
+		}
+		if err := p.storage.Save(ctx, page); err != nil {
+			return err
+		}
+		saved++
+	}
+
+	return p.tg.SendMessage(chatID, p.text(userID, msgImported, saved, createdFolders, duplicates, invalid))
+}
+
+// statusByName() returns the status from the export file, unknown statuses are unread
+func statusByName(name string) storage.Status {
+	for status, statusName := range statusNames {
+		if statusName == name {
+			return status
+		}
+	}
+
+	return storage.StatusUnread
+}
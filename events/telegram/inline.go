@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"strconv"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+)
+
+// Telegram показывает не больше 50 результатов за один ответ
+const inlineResultsLimit = 20
+
+// searchInline() answers "@bot query" with the user's links that match the query.
+// The offset of the next portion of results is passed through next_offset
+func (p *Processor) searchInline(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't search inline", err) }()
+
+	meta := r.inline
+
+	offset, err := strconv.Atoi(meta.Offset)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	pages, err := p.storage.Search(ctx, r.userID, r.text, inlineResultsLimit, offset)
+	if err != nil {
+		_ = p.tg.AnswerInlineQuery(meta.QueryID, nil, "")
+		return err
+	}
+
+	results := make([]tgClient.InlineQueryResultArticle, 0, len(pages))
+	for _, page := range pages {
+		description := page.Folder
+		if page.Note != "" {
+			description += " · " + page.Note
+		}
+		results = append(results, tgClient.InlineQueryResultArticle{
+			Type:        "article",
+			ID:          strconv.Itoa(page.ID),
+			Title:       linkTitle(page.URL),
+			Description: description,
+			URL:         page.URL,
+			InputMessageContent: tgClient.InputTextMessageContent{
+				MessageText: page.URL,
+			},
+		})
+	}
+
+	nextOffset := ""
+	if len(pages) == inlineResultsLimit {
+		nextOffset = strconv.Itoa(offset + inlineResultsLimit)
+	}
+
+	return p.tg.AnswerInlineQuery(meta.QueryID, results, nextOffset)
+}
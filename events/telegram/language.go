@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"context"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/i18n"
+)
+
+// languagesKeyboard() returns one button for each supported language
+func languagesKeyboard() [][]tgClient.InlineKeyboardButton {
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(i18n.Languages))
+	for _, lang := range i18n.Languages {
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: lang.Name(), CallbackData: string(lang)}})
+	}
+
+	return buttons
+}
+
+// setLanguage() saves the language chosen by the user, the answer is already in the new language
+func (p *Processor) setLanguage(ctx context.Context, meta *CallbackMeta, tag string) error {
+	lang, ok := i18n.Parse(tag)
+	if !ok {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+	}
+
+	if err := p.storage.SetLanguage(ctx, meta.UserID, string(lang)); err != nil {
+		return errhandling.Wrap("can't set language", err)
+	}
+	p.setUserLanguage(meta.UserID, lang)
+
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgLanguageChanged), nil)
+}
@@ -0,0 +1,468 @@
+package telegram
+
+import "github.com/hahaclassic/golang-telegram-bot.git/lib/i18n"
+
+// Тексты сообщений бота на всех поддерживаемых языках.
+// Сообщения без перевода берутся из английского каталога
+var messages = i18n.Catalog[msgKey]{
+	i18n.English: english,
+	i18n.Russian: russian,
+}
+
+var english = map[msgKey]string{
+	msgHelp: `With this bot, you can store your important links and sort them by folders😊
+
+To save the link:
+1. Create a folder using /create (use "/" for subfolders: Work/Go/Talks)
+2. Enter the link (example.com/article or https://example.com), send a message with several links or forward a post from a channel. Text after a single link is saved as its note: "example.com/article read before the meeting"
+3. Select the folder where you want to save the link
+(To save to an existing folder, just enter the link)
+
+To view the contents of a folder:
+1. Enter the show command
+2. Select the desired folder (folders with subfolders are marked with 📁, use the buttons at the top to go back)
+
+To delete a folder:
+1. Enter the command /delete_folder
+2. Select a folder
+!!! BE CAREFUL !!! this command will delete the folder, its subfolders and all their contents without the possibility of recovery
+
+To delete a link:
+1. Enter the command /delete
+2. Select a folder
+3. Select a link
+!!! BE CAREFUL !!! this command will delete the link without the possibility of recovery
+
+To abort the operation, type /cancel`,
+	msgHelpCommands: "Commands:",
+	msgHelpFooter: `Use the "Mark read" and "Archive" buttons under a link to keep your reading queue tidy. The number next to a folder is the count of unread links in it.
+
+To share a saved link in any chat, type @ and the bot's name, then words from the link or the folder name.
+
+To work on a folder with colleagues, create an invite with /share and send them the code. They enter /join with the code and find the folder in /shared.
+
+In a group the bot keeps links of the whole group. Address commands to the bot: /show@bot_name. Send links with /save@bot_name or turn on /capture to save every link posted in the group. Only administrators can delete, rename and move folders and links.
+
+To get a link again later, press "Remind" under it or enter /remind and send the time: "tomorrow 9:00", "in 3 days". The time is read in your time zone, set it with /timezone. Enter /digest to get a handful of unread links every day or every week.
+
+Saved links are checked in the background. Links that don't open anymore are marked with ⚠️, review and delete them with /dead. Enter /snapshots to keep a readable copy of every saved page in case it disappears.
+
+Enter /stats to see how many links you save and read and which sites you save most often.
+
+All commands are available in the menu next to the input field.
+Productive work!`,
+	msgHello: "Hi there!",
+
+	descCreate:       "create a folder",
+	descShow:         "show the contents of a folder",
+	descRename:       "rename folder",
+	descMove:         "move folder into another one",
+	descDelete:       "delete a link",
+	descDeleteFolder: "delete a folder with all its contents",
+	descRnd:          "output a random link from any folder and mark it read",
+	descUnread:       "list of links you haven't read yet",
+	descExport:       "download all your links as a file (JSON, CSV, Markdown or browser bookmarks)",
+	descImport:       "add links from a file exported from a browser, Pocket or this bot",
+	descShare:        "invite other users to a folder",
+	descJoin:         "join a shared folder by the invite code",
+	descShared:       "folders shared with you",
+	descCapture:      "save all links posted in the group (groups only)",
+	descRemind:       "remind about a link at the chosen time",
+	descTimezone:     "change the time zone of reminders",
+	descDigest:       "get a regular digest of your links",
+	descDead:         "review and delete broken links",
+	descSnapshots:    "turn on or off saving copies of pages",
+	descStats:        "statistics of your links",
+	descLanguage:     "change the language of the bot",
+	descHelp:         "help about the bot",
+	descRusHelp:      "help in Russian",
+	descCancel:       "abort the current operation",
+
+	msgUnknownCommand:    "Unknown command 🤔",
+	msgUnexpectedCommand: "Unexpected command 🤕",
+	msgFolderNotExists:   "This folder doesn't exist 🥺",
+	msgNoSavedPages:      "You have no saved pages 😢",
+	msgNoFolders:         "No existing folders 😢",
+	msgEmptyFolder:       "This folder is still empty 😢",
+	msgCantRename:        "Cannot be renamed. A folder with this name already exists 😧",
+	msgLongFolderName:    "The folder name is too long, enter something shorter 🥴",
+	msgNotAllowed:        "Sorry, this bot is private 🔒",
+	msgTooManyRequests:   "Too many requests, please wait a bit ⏳",
+	msgPageNotFound:      "This link no longer exists 🥺",
+	msgEmptyFolderName:   "The folder name can't be empty 🥴",
+	msgSlashInName:       "The name can't contain \"/\". Use /move to move the folder 🙃",
+	msgCantMove:          "Cannot be moved. A folder with this name already exists there 😧",
+	msgFileTooLarge:      "The file is too large, the limit is 20 MB 🥴",
+	msgCantImport:        "Can't read bookmarks from this file. Send a file exported from a browser, Pocket or this bot 🥺",
+	msgAccessDenied:      "You don't have access to this folder 🔒",
+	msgInviteNotFound:    "There is no invite with this code 🥺",
+	msgNoSharedFolders:   "No folders are shared with you yet. Ask a colleague for an invite code 😢",
+	msgGroupsOnly:        "This command works only in groups 🙃",
+	msgAdminsOnly:        "Only administrators of the group can do this 🔒",
+	msgLongNote:          "The note is too long, the limit is %d characters 🥴",
+	msgFinishOperation:   "Finish the current operation or enter /cancel first",
+	msgUnknownTime:       "I don't understand this time 🤔 Try \"tomorrow 9:00\", \"in 3 days\", \"friday 18:30\" or \"25.12 10:00\"",
+	msgPastTime:          "This time has already passed, enter a time in the future ⏳",
+	msgUnknownTimezone:   "Unknown time zone 🤔 Enter a name like Europe/Berlin or an offset like +3 or UTC-05:00",
+
+	msgFolderAlreadyExists: "This folder already exists 😌",
+	msgAlreadyExists:       "You already have this page in your list 😌",
+	msgAlsoInFolders:       "This link is also saved in: %s 🧐",
+	msgOwnFolder:           "This is your own folder 😌",
+
+	msgNewFolderCreated:   "New Folder created 😇",
+	msgSaved:              "Saved! 👌",
+	msgSavedSeveral:       "Saved links: %d, already in the folder: %d 👌",
+	msgFolderDeleted:      "Folder deleted 🫡",
+	msgPageDeleted:        "Link deleted 🫡",
+	msgFolderRenamed:      "Folder renamed 👌",
+	msgFolderMoved:        "Folder moved 👌",
+	msgOperationCancelled: "Operation cancelled 🤓",
+	msgMarkedRead:         "Marked as read ✅",
+	msgArchived:           "Link archived 🗄",
+	msgNoUnread:           "You have no unread links 🥳",
+	msgUnreadList:         "Unread links:",
+	msgExportReady:        "Export is ready 📦",
+	msgExported:           "Links: %d, folders: %d",
+	msgImported:           "Imported links: %d, new folders: %d\nSkipped duplicates: %d, invalid links: %d 📥",
+	msgLanguageChanged:    "The bot will speak English now 👌",
+	msgInviteCreated:      "The invite to \"%s\" (%s) is ready 🎟\nSend the code to your colleagues, they need to enter:\n/join %s",
+	msgJoined:             "You have joined \"%s\" as %s 🤝\nThe folder is in /shared",
+	msgMemberJoined:       "%s has joined your folder \"%s\" as %s 🤝",
+	msgLinksAdded:         "%s added to the shared folder \"%s\":\n%s",
+	msgCaptureOn:          "Now I save every link posted in the group to the \"%s\" folder 📥",
+	msgCaptureOff:         "Links from the group aren't saved automatically anymore 📴",
+	msgNoteSaved:          "Note saved 📝",
+	msgNoteRemoved:        "Note removed 🫡",
+	msgReminderSet:        "I'll remind you on %s (%s) ⏰",
+	msgReminder:           "⏰ You asked to remind you:\n%s",
+	msgTimezoneChanged:    "Time zone changed to %s, the time there is %s 🕰",
+	msgDigestSaved:        "Digest is set up: %s 📬",
+	msgDigestOff:          "Digest turned off 📴",
+	msgDigest:             "📬 Your digest:",
+	msgNoDeadLinks:        "No broken links found 🥳",
+	msgDeadRemoved:        "Broken links deleted: %d 🫡",
+	msgSnapshotsOn:        "Now I save a readable copy of every link you save. If the page disappears, press \"Copy\" under the link or in /dead 📄",
+	msgSnapshotsOff:       "Copies of pages aren't saved anymore 📴",
+	msgSnapshot:           "📄 Copy of %s saved on %s",
+	msgNoSnapshot:         "There is no copy of this page",
+	msgStats:              "📊 Saved links: %d\nUnread: %d, read: %d, archived: %d",
+	msgStatsFolders:       "📁 Links by folder:",
+	msgStatsMore:          "…and %d more",
+	msgStatsWeeks:         "📅 Saved per week:",
+	msgStatsDomains:       "🌐 Most saved sites:",
+	msgStatsOldest:        "⏳ The oldest unread link, saved on %s:\n%s",
+	msgStatsOldestUndated: "⏳ The oldest unread link:\n%s",
+
+	msgChooseFolder:        "Choose folder",
+	msgChooseLink:          "Choose link for deletion",
+	msgEnterFolderName:     "Enter the folder name",
+	msgEnterNewFolderName:  "Enter new folder name",
+	msgChooseNewParent:     "Choose where to move the folder",
+	msgChooseFormat:        "Choose the export format",
+	msgSendFile:            "Send the file with your bookmarks: an export from a browser (HTML), Pocket (HTML or CSV) or this bot (JSON or CSV)",
+	msgChooseLanguage:      "Choose the language",
+	msgChooseRole:          "Choose what the invited users can do",
+	msgJoinUsage:           "Enter the invite code after the command: /join CODE",
+	msgEnterNote:           "Enter the note for %s",
+	msgCurrentNote:         "Current note: %s\nSend \"%s\" to remove it",
+	msgChooseRemindLink:    "Choose the link to remind about",
+	msgEnterRemindTime:     "When should I remind you? For example: \"tomorrow 9:00\", \"in 3 days\", \"friday 18:30\", \"25.12 10:00\"\nTime zone: %s, change it with /timezone",
+	msgEnterTimezone:       "Enter your time zone: a name like Europe/Berlin or an offset like +3\nCurrent time zone: %s",
+	msgChooseDigestDay:     "Current digest: %s\n\nChoose when to send the digest of unread links. To turn it off, enter /digest off",
+	msgChooseDigestHour:    "Choose the hour (time zone %s)",
+	msgDeadList:            "Broken links: %d. Press 🗑 with the number of a link to delete it",
+	msgChooseDigestFolders: "Choose the folders for the digest and press \"Done\". If no folder is chosen, links are taken from all folders",
+
+	msgHintCancel:           "or enter /cancel to abort operation.",
+	msgHintRename:           "Select the folder you want to rename",
+	msgHintMove:             "Select the folder you want to move",
+	msgHintMoveTo:           "Select the folder where you want to move it",
+	msgHintDeleteLinkFolder: "Select the folder where you want to delete the link",
+	msgHintDeleteLink:       "Select the link you want to delete",
+	msgHintShow:             "Select the folder whose contents you want to see",
+	msgHintDeleteFolder:     "Select the folder you want to delete",
+	msgHintExport:           "Select the export format",
+	msgHintImport:           "Send the file with your bookmarks",
+	msgHintLanguage:         "Select the language",
+	msgHintShare:            "Select the folder you want to share",
+	msgHintShareRole:        "Select what the invited users can do",
+	msgHintShared:           "Select the shared folder you want to open",
+	msgHintNote:             "Send the text of the note",
+	msgHintRemind:           "Select the folder with the link",
+	msgHintRemindLink:       "Select the link to remind about",
+	msgHintRemindTime:       "Send the time of the reminder",
+	msgHintTimezone:         "Send your time zone",
+	msgHintDigest:           "Select when to send the digest",
+	msgHintDigestHour:       "Select the hour of the digest",
+	msgHintDigestFolders:    "Select the folders for the digest and press \"Done\"",
+	msgHintDead:             "Delete broken links with the buttons or press \"Done\"",
+
+	btnMarkRead:   "✅ Mark read",
+	btnArchive:    "🗄 Archive",
+	btnRootFolder: "🏠 Root",
+	btnOpenFolder: "📂 Open links here",
+	btnNetscape:   "Browser (HTML)",
+
+	btnRoleViewer:      "👀 View links",
+	btnRoleContributor: "✍️ View and add links",
+	btnRoleOwner:       "👑 View, add links and invite",
+	btnAddNote:         "📝 Add note",
+	btnEditNote:        "📝 Edit note",
+	btnRemind:          "⏰ Remind",
+	btnSnapshot:        "📄 Copy",
+	btnEveryDay:        "Every day",
+	btnDone:            "👌 Done",
+	btnPurge:           "🗑 Delete all",
+
+	roleViewer:      "viewer",
+	roleContributor: "contributor",
+	roleOwner:       "owner",
+
+	digestDaily:      "every day at %s",
+	digestWeekly:     "every %s at %s",
+	digestAllFolders: "from all folders",
+	digestFolders:    "from folders: %s",
+	digestNotSet:     "turned off",
+
+	deadUnreachable: "the site doesn't respond",
+
+	weekdaySunday:    "Sunday",
+	weekdayMonday:    "Monday",
+	weekdayTuesday:   "Tuesday",
+	weekdayWednesday: "Wednesday",
+	weekdayThursday:  "Thursday",
+	weekdayFriday:    "Friday",
+	weekdaySaturday:  "Saturday",
+
+	msgOriginalPost: "original post",
+}
+
+var russian = map[msgKey]string{
+	msgHelp: `С помощью данного бота ты можешь хранить свои важные ссылки и сортировать их по папкам😊
+
+Чтобы сохранить ссылку:
+1. Создайте папку с помощью /create (используйте "/" для вложенных папок: Work/Go/Talks)
+2. Введите ссылку (example.com/article или https://example.com), отправьте сообщение с несколькими ссылками или перешлите пост из канала. Текст после одной ссылки сохраняется как заметка к ней: "example.com/article прочитать перед встречей"
+3. Выберите папку, в которую хотите сохранить ссылку
+(Чтобы сохранить в уже существующую папку, просто введите ссылку)
+
+Чтобы посмотреть содержимое папки:
+1. Введите команду /show
+2. Выберите нужную папку (папки с вложенными папками отмечены 📁, кнопки сверху возвращают назад)
+
+Чтобы удалить папку:
+1. Введите команду /delete_folder
+2. Выберите папку
+!!! БУДЬТЕ ВНИМАТЕЛЬНЫ !!! данная команда удалит папку, вложенные папки и все их содержимое без возможности восстановления
+
+Чтобы удалить ссылку:
+1. Введите команду /delete
+2. Выберите папку
+3. Выберите ссылку
+!!! БУДЬТЕ ВНИМАТЕЛЬНЫ !!! данная команда удалит ссылку без возможности восстановления
+
+Чтобы прервать операцию, введите /cancel`,
+	msgHelpCommands: "Команды:",
+	msgHelpFooter: `Используйте кнопки "Прочитано" и "В архив" под ссылкой, чтобы следить за списком чтения. Число рядом с папкой - количество непрочитанных ссылок в ней.
+
+Чтобы отправить сохраненную ссылку в любой чат, введите @ и имя бота, а затем слова из ссылки или названия папки.
+
+Чтобы работать с папкой вместе с коллегами, создайте приглашение командой /share и отправьте им код. Они вводят /join с кодом и находят папку в /shared.
+
+В группе бот хранит ссылки всей группы. Обращайтесь к боту по имени: /show@имя_бота. Отправляйте ссылки командой /save@имя_бота или включите /capture, чтобы сохранять все ссылки из сообщений группы. Удалять, переименовывать и перемещать папки и ссылки могут только администраторы.
+
+Чтобы получить ссылку снова позже, нажмите "Напомнить" под ней или введите /remind и отправьте время: "завтра 9:00", "через 3 дня". Время считается в вашем часовом поясе, его можно задать командой /timezone. Введите /digest, чтобы каждый день или каждую неделю получать несколько непрочитанных ссылок.
+
+Сохраненные ссылки проверяются в фоне. Ссылки, которые больше не открываются, отмечены ⚠️, посмотреть и удалить их можно командой /dead. Введите /snapshots, чтобы хранить копию текста каждой сохраненной страницы на случай, если она пропадет.
+
+Введите /stats, чтобы узнать, сколько ссылок вы сохраняете и читаете и какие сайты сохраняете чаще всего.
+
+Все команды доступны в меню рядом с полем ввода.
+Продуктивной работы!`,
+	msgHello: "Привет!",
+
+	descCreate:       "создание папки",
+	descShow:         "просмотр содержимого папки",
+	descRename:       "переименование папки",
+	descMove:         "перемещение папки в другую папку",
+	descDelete:       "удаление ссылки",
+	descDeleteFolder: "удаление папки со всем содержимым",
+	descRnd:          "вывод случайной ссылки из любой папки, она отмечается прочитанной",
+	descUnread:       "список непрочитанных ссылок",
+	descExport:       "выгрузка всех ссылок файлом (JSON, CSV, Markdown или закладки браузера)",
+	descImport:       "загрузка ссылок из файла, выгруженного из браузера, Pocket или этого бота",
+	descShare:        "приглашение других пользователей в папку",
+	descJoin:         "вход в общую папку по коду приглашения",
+	descShared:       "папки, к которым вам открыли доступ",
+	descCapture:      "сохранение всех ссылок из сообщений группы (только в группах)",
+	descRemind:       "напоминание о ссылке в выбранное время",
+	descTimezone:     "смена часового пояса напоминаний",
+	descDigest:       "регулярная подборка ваших ссылок",
+	descDead:         "просмотр и удаление битых ссылок",
+	descSnapshots:    "включить или выключить сохранение копий страниц",
+	descStats:        "статистика ваших ссылок",
+	descLanguage:     "смена языка бота",
+	descHelp:         "справка о боте",
+	descRusHelp:      "справка на русском",
+	descCancel:       "прервать текущую операцию",
+
+	msgUnknownCommand:    "Неизвестная команда 🤔",
+	msgUnexpectedCommand: "Неожиданная команда 🤕",
+	msgFolderNotExists:   "Такой папки не существует 🥺",
+	msgNoSavedPages:      "У вас нет сохраненных ссылок 😢",
+	msgNoFolders:         "Папок пока нет 😢",
+	msgEmptyFolder:       "Эта папка пока пуста 😢",
+	msgCantRename:        "Нельзя переименовать. Папка с таким названием уже существует 😧",
+	msgLongFolderName:    "Слишком длинное название папки, введите что-нибудь покороче 🥴",
+	msgNotAllowed:        "Извините, это приватный бот 🔒",
+	msgTooManyRequests:   "Слишком много запросов, подождите немного ⏳",
+	msgPageNotFound:      "Этой ссылки больше нет 🥺",
+	msgEmptyFolderName:   "Название папки не может быть пустым 🥴",
+	msgSlashInName:       "Название не может содержать \"/\". Чтобы переместить папку, используйте /move 🙃",
+	msgCantMove:          "Нельзя переместить. Там уже есть папка с таким названием 😧",
+	msgFileTooLarge:      "Файл слишком большой, ограничение - 20 МБ 🥴",
+	msgCantImport:        "Не удалось прочитать закладки из файла. Отправьте файл, выгруженный из браузера, Pocket или этого бота 🥺",
+	msgAccessDenied:      "У вас нет доступа к этой папке 🔒",
+	msgInviteNotFound:    "Приглашения с таким кодом нет 🥺",
+	msgNoSharedFolders:   "Вам пока не открыли доступ ни к одной папке. Попросите у коллеги код приглашения 😢",
+	msgGroupsOnly:        "Эта команда работает только в группах 🙃",
+	msgAdminsOnly:        "Это могут делать только администраторы группы 🔒",
+	msgLongNote:          "Слишком длинная заметка, ограничение - %d символов 🥴",
+	msgFinishOperation:   "Сначала завершите текущую операцию или введите /cancel",
+	msgUnknownTime:       "Не понимаю это время 🤔 Попробуйте \"завтра 9:00\", \"через 3 дня\", \"пятница 18:30\" или \"25.12 10:00\"",
+	msgPastTime:          "Это время уже прошло, введите время в будущем ⏳",
+	msgUnknownTimezone:   "Неизвестный часовой пояс 🤔 Введите название, например Europe/Moscow, или смещение, например +3 или UTC-05:00",
+
+	msgFolderAlreadyExists: "Такая папка уже существует 😌",
+	msgAlreadyExists:       "Эта ссылка уже есть в вашем списке 😌",
+	msgAlsoInFolders:       "Эта ссылка также сохранена в: %s 🧐",
+	msgOwnFolder:           "Это ваша собственная папка 😌",
+
+	msgNewFolderCreated:   "Папка создана 😇",
+	msgSaved:              "Сохранено! 👌",
+	msgSavedSeveral:       "Сохранено ссылок: %d, уже были в папке: %d 👌",
+	msgFolderDeleted:      "Папка удалена 🫡",
+	msgPageDeleted:        "Ссылка удалена 🫡",
+	msgFolderRenamed:      "Папка переименована 👌",
+	msgFolderMoved:        "Папка перемещена 👌",
+	msgOperationCancelled: "Операция отменена 🤓",
+	msgMarkedRead:         "Отмечено как прочитанное ✅",
+	msgArchived:           "Ссылка в архиве 🗄",
+	msgNoUnread:           "Непрочитанных ссылок нет 🥳",
+	msgUnreadList:         "Непрочитанные ссылки:",
+	msgExportReady:        "Выгрузка готова 📦",
+	msgExported:           "Ссылок: %d, папок: %d",
+	msgImported:           "Загружено ссылок: %d, новых папок: %d\nПропущено повторов: %d, некорректных ссылок: %d 📥",
+	msgLanguageChanged:    "Теперь бот говорит по-русски 👌",
+	msgInviteCreated:      "Приглашение в \"%s\" (%s) готово 🎟\nОтправьте код коллегам, им нужно ввести:\n/join %s",
+	msgJoined:             "Вы присоединились к \"%s\" как %s 🤝\nПапка находится в /shared",
+	msgMemberJoined:       "%s присоединяется к вашей папке \"%s\" как %s 🤝",
+	msgLinksAdded:         "%s добавляет в общую папку \"%s\":\n%s",
+	msgCaptureOn:          "Теперь я сохраняю все ссылки из сообщений группы в папку \"%s\" 📥",
+	msgCaptureOff:         "Ссылки из группы больше не сохраняются автоматически 📴",
+	msgNoteSaved:          "Заметка сохранена 📝",
+	msgNoteRemoved:        "Заметка удалена 🫡",
+	msgReminderSet:        "Напомню %s (%s) ⏰",
+	msgReminder:           "⏰ Вы просили напомнить:\n%s",
+	msgTimezoneChanged:    "Часовой пояс изменен на %s, там сейчас %s 🕰",
+	msgDigestSaved:        "Подборка настроена: %s 📬",
+	msgDigestOff:          "Подборка выключена 📴",
+	msgDigest:             "📬 Ваша подборка:",
+	msgNoDeadLinks:        "Битых ссылок не найдено 🥳",
+	msgDeadRemoved:        "Удалено битых ссылок: %d 🫡",
+	msgSnapshotsOn:        "Теперь я сохраняю копию текста каждой сохраненной ссылки. Если страница пропадет, нажмите \"Копия\" под ссылкой или в /dead 📄",
+	msgSnapshotsOff:       "Копии страниц больше не сохраняются 📴",
+	msgSnapshot:           "📄 Копия %s от %s",
+	msgNoSnapshot:         "Копии этой страницы нет",
+	msgStats:              "📊 Сохранено ссылок: %d\nНепрочитанных: %d, прочитанных: %d, в архиве: %d",
+	msgStatsFolders:       "📁 Ссылки по папкам:",
+	msgStatsMore:          "…и еще %d",
+	msgStatsWeeks:         "📅 Сохранено по неделям:",
+	msgStatsDomains:       "🌐 Чаще всего сохраняются сайты:",
+	msgStatsOldest:        "⏳ Самая старая непрочитанная ссылка, сохранена %s:\n%s",
+	msgStatsOldestUndated: "⏳ Самая старая непрочитанная ссылка:\n%s",
+
+	msgChooseFolder:        "Выберите папку",
+	msgChooseLink:          "Выберите ссылку для удаления",
+	msgEnterFolderName:     "Введите название папки",
+	msgEnterNewFolderName:  "Введите новое название папки",
+	msgChooseNewParent:     "Выберите, куда переместить папку",
+	msgChooseFormat:        "Выберите формат выгрузки",
+	msgSendFile:            "Отправьте файл с закладками: выгрузку из браузера (HTML), Pocket (HTML или CSV) или этого бота (JSON или CSV)",
+	msgChooseLanguage:      "Выберите язык",
+	msgChooseRole:          "Выберите, что смогут делать приглашенные",
+	msgJoinUsage:           "Введите код приглашения после команды: /join КОД",
+	msgEnterNote:           "Введите заметку к %s",
+	msgCurrentNote:         "Текущая заметка: %s\nОтправьте \"%s\", чтобы удалить ее",
+	msgChooseRemindLink:    "Выберите ссылку, о которой напомнить",
+	msgEnterRemindTime:     "Когда напомнить? Например: \"завтра 9:00\", \"через 3 дня\", \"пятница 18:30\", \"25.12 10:00\"\nЧасовой пояс: %s, изменить его можно командой /timezone",
+	msgEnterTimezone:       "Введите часовой пояс: название, например Europe/Moscow, или смещение, например +3\nТекущий часовой пояс: %s",
+	msgChooseDigestDay:     "Текущая подборка: %s\n\nВыберите, когда присылать подборку непрочитанных ссылок. Чтобы выключить ее, введите /digest off",
+	msgChooseDigestHour:    "Выберите час (часовой пояс %s)",
+	msgDeadList:            "Битых ссылок: %d. Нажмите 🗑 с номером ссылки, чтобы удалить ее",
+	msgChooseDigestFolders: "Выберите папки для подборки и нажмите \"Готово\". Если не выбрать ни одной папки, ссылки берутся из всех папок",
+
+	msgHintCancel:           "или введите /cancel, чтобы прервать операцию.",
+	msgHintRename:           "Выберите папку, которую хотите переименовать,",
+	msgHintMove:             "Выберите папку, которую хотите переместить,",
+	msgHintMoveTo:           "Выберите папку, в которую хотите ее переместить,",
+	msgHintDeleteLinkFolder: "Выберите папку, из которой хотите удалить ссылку,",
+	msgHintDeleteLink:       "Выберите ссылку, которую хотите удалить",
+	msgHintShow:             "Выберите папку, содержимое которой хотите посмотреть,",
+	msgHintDeleteFolder:     "Выберите папку, которую хотите удалить,",
+	msgHintExport:           "Выберите формат выгрузки",
+	msgHintImport:           "Отправьте файл с закладками",
+	msgHintLanguage:         "Выберите язык",
+	msgHintShare:            "Выберите папку, к которой хотите открыть доступ,",
+	msgHintShareRole:        "Выберите, что смогут делать приглашенные,",
+	msgHintShared:           "Выберите общую папку, которую хотите открыть,",
+	msgHintNote:             "Отправьте текст заметки",
+	msgHintRemind:           "Выберите папку со ссылкой,",
+	msgHintRemindLink:       "Выберите ссылку, о которой напомнить,",
+	msgHintRemindTime:       "Отправьте время напоминания",
+	msgHintTimezone:         "Отправьте часовой пояс",
+	msgHintDigest:           "Выберите, когда присылать подборку,",
+	msgHintDigestHour:       "Выберите час подборки,",
+	msgHintDigestFolders:    "Выберите папки для подборки и нажмите \"Готово\",",
+	msgHintDead:             "Удалите битые ссылки кнопками или нажмите \"Готово\",",
+
+	btnMarkRead:   "✅ Прочитано",
+	btnArchive:    "🗄 В архив",
+	btnRootFolder: "🏠 Корень",
+	btnOpenFolder: "📂 Открыть ссылки",
+	btnNetscape:   "Браузер (HTML)",
+
+	btnRoleViewer:      "👀 Просмотр ссылок",
+	btnRoleContributor: "✍️ Просмотр и добавление ссылок",
+	btnRoleOwner:       "👑 Просмотр, добавление и приглашения",
+	btnAddNote:         "📝 Добавить заметку",
+	btnEditNote:        "📝 Изменить заметку",
+	btnRemind:          "⏰ Напомнить",
+	btnSnapshot:        "📄 Копия",
+	btnEveryDay:        "Каждый день",
+	btnDone:            "👌 Готово",
+	btnPurge:           "🗑 Удалить все",
+
+	roleViewer:      "читатель",
+	roleContributor: "автор",
+	roleOwner:       "владелец",
+
+	digestDaily:      "каждый день в %s",
+	digestWeekly:     "раз в неделю (%s) в %s",
+	digestAllFolders: "из всех папок",
+	digestFolders:    "из папок: %s",
+	digestNotSet:     "выключена",
+
+	deadUnreachable: "сайт не отвечает",
+
+	weekdaySunday:    "воскресенье",
+	weekdayMonday:    "понедельник",
+	weekdayTuesday:   "вторник",
+	weekdayWednesday: "среда",
+	weekdayThursday:  "четверг",
+	weekdayFriday:    "пятница",
+	weekdaySaturday:  "суббота",
+
+	msgOriginalPost: "исходный пост",
+}
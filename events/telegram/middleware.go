@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// Метрики доступны по /debug/vars, если в main запущен http сервер
+var (
+	metricRequests = expvar.NewMap("bot_requests")        // Количество запросов по маршрутам
+	metricErrors   = expvar.NewMap("bot_errors")          // Количество ошибок по маршрутам
+	metricDuration = expvar.NewMap("bot_duration_ms")     // Суммарное время обработки по маршрутам
+	metricDenied   = expvar.NewMap("bot_denied_requests") // Запросы, отклоненные авторизацией и ограничением частоты
+)
+
+var ErrPanic = errors.New("handler panicked")
+
+// recovery() turns a panic in the handler into an error, so one bad request doesn't stop the bot
+func (p *Processor) recovery(route string, next handler) handler {
+	return func(ctx context.Context, r *request) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Printf("panic in '%s': %v\n%s", route, rec, debug.Stack())
+				err = fmt.Errorf("%w: %s: %v", ErrPanic, route, rec)
+			}
+		}()
+
+		return next(ctx, r)
+	}
+}
+
+func (p *Processor) metrics(route string, next handler) handler {
+	return func(ctx context.Context, r *request) error {
+		start := time.Now()
+		err := next(ctx, r)
+
+		metricRequests.Add(route, 1)
+		metricDuration.Add(route, time.Since(start).Milliseconds())
+		if err != nil {
+			metricErrors.Add(route, 1)
+		}
+
+		return err
+	}
+}
+
+func (p *Processor) logging(route string, next handler) handler {
+	return func(ctx context.Context, r *request) error {
+		start := time.Now()
+		err := next(ctx, r)
+
+		if err != nil {
+			log.Printf("'%s' from '%d' failed in %s: %s", route, r.userID, time.Since(start), err)
+		} else {
+			log.Printf("'%s' from '%d' handled in %s", route, r.userID, time.Since(start))
+		}
+
+		return err
+	}
+}
+
+// authorization() lets only allowed users use the bot. Without the list everyone is allowed
+func (p *Processor) authorization(route string, next handler) handler {
+	return func(ctx context.Context, r *request) error {
+		if len(p.allowed) == 0 || p.allowed[r.senderID] {
+			return next(ctx, r)
+		}
+
+		metricDenied.Add("unauthorized", 1)
+
+		return p.deny(r, msgNotAllowed)
+	}
+}
+
+func (p *Processor) rateLimit(route string, next handler) handler {
+	return func(ctx context.Context, r *request) error {
+		if p.limiter.Allow(r.senderID) {
+			return next(ctx, r)
+		}
+
+		metricDenied.Add("rate_limit", 1)
+
+		return p.deny(r, msgTooManyRequests)
+	}
+}
+
+// deny() tells the user why the request isn't handled
+func (p *Processor) deny(r *request, key msgKey) error {
+	if r.callback != nil {
+		return p.tg.AnswerCallbackQueryWithText(r.callback.QueryID, p.text(r.userID, key))
+	}
+	if r.inline != nil {
+		// На inline запрос нельзя ответить сообщением, пользователь просто не увидит результатов
+		return p.tg.AnswerInlineQuery(r.inline.QueryID, nil, "")
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, key))
+}
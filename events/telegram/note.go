@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const (
+	maxNoteLength = 500 // Символов
+	noteRemove    = "-" // Ответ, который удаляет заметку
+)
+
+// noteKeyboard() returns the button that adds or changes the note of the link
+func (p *Processor) noteKeyboard(page *storage.Page) [][]tgClient.InlineKeyboardButton {
+	return [][]tgClient.InlineKeyboardButton{{p.noteButton(page)}}
+}
+
+func (p *Processor) noteButton(page *storage.Page) tgClient.InlineKeyboardButton {
+	text := p.text(page.UserID, btnAddNote)
+	if page.Note != "" {
+		text = p.text(page.UserID, btnEditNote)
+	}
+
+	return tgClient.InlineKeyboardButton{Text: text, CallbackData: pageActionData(NoteCmd, page.ID)}
+}
+
+// messageNote() returns the comment after the link if the message is a single link with a comment:
+// "example.com/article read before the meeting". The command before the link is skipped
+func (p *Processor) messageNote(text string, links []string) string {
+	if len(links) != 1 {
+		return ""
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "/") {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return ""
+	}
+
+	if link, err := p.links.Normalize(fields[0]); err != nil || link != links[0] {
+		return ""
+	}
+
+	return cutNote(strings.Join(fields[1:], " "))
+}
+
+func cutNote(note string) string {
+	if utf8.RuneCountInString(note) <= maxNoteLength {
+		return note
+	}
+
+	return string([]rune(note)[:maxNoteLength])
+}
+
+// askNote() starts changing the note of the link. The button is under the link,
+// so it can be pressed during another operation, which has to be finished first
+func (p *Processor) askNote(ctx context.Context, meta *CallbackMeta, id int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't ask note", err) }()
+
+	if !p.states.Can(meta.session(), fsm.Input(NoteCmd)) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgFinishOperation))
+	}
+
+	page, err := p.storage.GetPage(ctx, meta.UserID, id)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgPageNotFound))
+	}
+	if err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+
+	if _, err := p.states.Fire(meta.session(), fsm.Input(NoteCmd), strconv.Itoa(id)); err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+	_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+
+	message := p.text(meta.UserID, msgEnterNote, page.URL)
+	if page.Note != "" {
+		message += "\n\n" + p.text(meta.UserID, msgCurrentNote, page.Note, noteRemove)
+	}
+
+	return p.tg.SendMessage(meta.ChatID, message)
+}
+
+// setNote() saves the note of the link, "-" removes the note
+func (p *Processor) setNote(ctx context.Context, chatID int, userID int, rawID string, note string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't set note", err) }()
+
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return p.tg.SendMessage(chatID, p.text(userID, msgPageNotFound))
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return p.tg.SendMessage(chatID, p.text(userID, msgUnexpectedCommand))
+	}
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return p.tg.SendMessage(chatID, p.text(userID, msgLongNote, maxNoteLength))
+	}
+
+	message := p.text(userID, msgNoteSaved)
+	if note == noteRemove {
+		note, message = "", p.text(userID, msgNoteRemoved)
+	}
+
+	err = p.storage.SetNote(ctx, userID, id, note)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.tg.SendMessage(chatID, p.text(userID, msgPageNotFound))
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.tg.SendMessage(chatID, message)
+}
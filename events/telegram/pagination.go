@@ -0,0 +1,181 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+)
+
+const (
+	btnPrevPage = "◀️"
+	btnNextPage = "▶️"
+)
+
+// pagination describes the shown page of a long list of buttons or links.
+// Pages are numbered from 0, the user sees them numbered from 1
+type pagination struct {
+	current int
+	total   int
+	size    int
+}
+
+func newPagination(page, size, items int) pagination {
+	total := (items + size - 1) / size
+	if total == 0 {
+		total = 1
+	}
+
+	if page >= total {
+		page = total - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	return pagination{
+		current: page,
+		total:   total,
+		size:    size,
+	}
+}
+
+// offset() returns the index of the first item on the current page
+func (pg pagination) offset() int {
+	return pg.current * pg.size
+}
+
+// bounds() returns indices of the current page in a list of n items
+func (pg pagination) bounds(n int) (from, to int) {
+	from, to = pg.offset(), pg.offset()+pg.size
+	if from > n {
+		from = n
+	}
+	if to > n {
+		to = n
+	}
+
+	return from, to
+}
+
+// indicator() returns the page number in the "2/5" format
+func (pg pagination) indicator() string {
+	return strconv.Itoa(pg.current+1) + "/" + strconv.Itoa(pg.total)
+}
+
+// buttons() returns the row with prev/next buttons and the page indicator.
+// data() builds callback data of the button leading to the page. The indicator leads nowhere:
+// showing the same page again is refused by Telegram as "message is not modified"
+func (pg pagination) buttons(data func(page int) string) []tgClient.InlineKeyboardButton {
+	if pg.total <= 1 {
+		return nil
+	}
+
+	row := []tgClient.InlineKeyboardButton{}
+
+	if pg.current > 0 {
+		row = append(row, tgClient.InlineKeyboardButton{Text: btnPrevPage, CallbackData: data(pg.current - 1)})
+	}
+
+	row = append(row, tgClient.InlineKeyboardButton{Text: pg.indicator(), CallbackData: PageIndicatorCmd})
+
+	if pg.current < pg.total-1 {
+		row = append(row, tgClient.InlineKeyboardButton{Text: btnNextPage, CallbackData: data(pg.current + 1)})
+	}
+
+	return row
+}
+
+// withPages() appends the pagination row to the keyboard
+func (pg pagination) withPages(buttons [][]tgClient.InlineKeyboardButton, data func(page int) string) [][]tgClient.InlineKeyboardButton {
+	if row := pg.buttons(data); row != nil {
+		buttons = append(buttons, row)
+	}
+
+	return buttons
+}
+
+// pageData() returns callback data for pages of the list shown during the current operation
+func pageData(page int) string {
+	return PageCmd + " " + strconv.Itoa(page)
+}
+
+// pageNumber() parses callback data of the pagination buttons
+func pageNumber(data string) (int, bool) {
+	return numberArg(data, PageCmd)
+}
+
+// unreadPageData() returns callback data for pages of the unread links
+func unreadPageData(page int) string {
+	return UnreadPageCmd + " " + strconv.Itoa(page)
+}
+
+// unreadPage() parses callback data of the unread links pagination
+func unreadPage(data string) (int, bool) {
+	return numberArg(data, UnreadPageCmd)
+}
+
+// numberArg() parses "<cmd> <n>" callback data
+func numberArg(data string, cmd string) (int, bool) {
+	arg, ok := strings.CutPrefix(data, cmd+" ")
+	if !ok {
+		return 0, false
+	}
+
+	page, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, false
+	}
+
+	return page, true
+}
+
+// folderPageData() returns callback data for pages of the folder contents.
+// They are shown after the operation is finished, so the folder is passed in the data
+func folderPageData(folderID int) func(page int) string {
+	return func(page int) string {
+		return ShowPageCmd + " " + strconv.Itoa(folderID) + " " + strconv.Itoa(page)
+	}
+}
+
+// folderPage() parses callback data of the folder contents pagination
+func folderPage(data string) (folderID int, page int, ok bool) {
+	return listPage(data, ShowPageCmd)
+}
+
+// sharedPageData() returns callback data for pages of the shared folder contents
+func sharedPageData(id int) func(page int) string {
+	return func(page int) string {
+		return SharedPageCmd + " " + strconv.Itoa(id) + " " + strconv.Itoa(page)
+	}
+}
+
+// sharedPage() parses callback data of the shared folder contents pagination
+func sharedPage(data string) (id int, page int, ok bool) {
+	return listPage(data, SharedPageCmd)
+}
+
+// listPage() parses "<cmd> <id> <n>" callback data of the lists shown outside operations
+func listPage(data string, cmd string) (id int, page int, ok bool) {
+	args, ok := strings.CutPrefix(data, cmd+" ")
+	if !ok {
+		return 0, 0, false
+	}
+
+	rawID, num, ok := strings.Cut(args, " ")
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	page, err = strconv.Atoi(num)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return id, page, true
+}
@@ -0,0 +1,220 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/when"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Напоминание, которое не удалось отправить за это время (бот был выключен, пользователь
+// заблокировал бота), удаляется: ссылка, пришедшая на день позже, уже не нужна
+const reminderExpiry = 24 * time.Hour
+
+const reminderTimeLayout = "02.01.2006 15:04"
+
+// remindButton() returns the button that sets a reminder about the link
+func (p *Processor) remindButton(page *storage.Page) tgClient.InlineKeyboardButton {
+	return tgClient.InlineKeyboardButton{Text: p.text(page.UserID, btnRemind), CallbackData: pageActionData(RemindTimeCmd, page.ID)}
+}
+
+// location() returns the time zone of the user, UTC if the user hasn't chosen it
+func (p *Processor) location(ctx context.Context, userID int) (*time.Location, error) {
+	name, err := p.storage.GetTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := when.Location(name)
+	if err != nil {
+		// Зона из хранилища могла пропасть из базы часовых поясов
+		return time.UTC, nil
+	}
+
+	return loc, nil
+}
+
+// askRemindTime() starts setting a reminder by the button under the link. Like the note,
+// the reminder can't be set in the middle of another operation
+func (p *Processor) askRemindTime(ctx context.Context, meta *CallbackMeta, id int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't ask remind time", err) }()
+
+	if !p.states.Can(meta.session(), fsm.Input(RemindTimeCmd)) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgFinishOperation))
+	}
+
+	page, err := p.storage.GetPage(ctx, meta.UserID, id)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgPageNotFound))
+	}
+	if err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+
+	if _, err := p.states.Fire(meta.session(), fsm.Input(RemindTimeCmd), strconv.Itoa(id)); err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+	_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+
+	prompt, err := p.remindPrompt(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	return p.tg.SendMessage(meta.ChatID, page.URL+"\n\n"+prompt)
+}
+
+// chooseRemindTime() asks the time of the reminder about the link chosen in /remind
+func (p *Processor) chooseRemindTime(ctx context.Context, meta *CallbackMeta) error {
+	prompt, err := p.remindPrompt(ctx, meta.UserID)
+	if err != nil {
+		return errhandling.Wrap("can't choose remind time", err)
+	}
+
+	return p.show(meta.ChatID, meta.MessageID, prompt, nil)
+}
+
+// remindPrompt() returns the request for the time with examples and the time zone of the user
+func (p *Processor) remindPrompt(ctx context.Context, userID int) (string, error) {
+	loc, err := p.location(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return p.text(userID, msgEnterRemindTime, loc.String()), nil
+}
+
+// remind() saves the reminder about the link at the time entered by the user.
+// If the time can't be read, the operation waits for another answer
+func (p *Processor) remind(ctx context.Context, r *request, rawID string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't set reminder", err) }()
+
+	loc, err := p.location(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+
+	at, err := when.Parse(r.text, p.clock.Now().In(loc))
+	if errors.Is(err, when.ErrPastTime) {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgPastTime))
+	}
+	if err != nil {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgUnknownTime))
+	}
+
+	if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgPageNotFound))
+	}
+
+	page, err := p.storage.GetPage(ctx, r.userID, id)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgPageNotFound))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.storage.AddReminder(ctx, &storage.Reminder{UserID: r.userID, PageID: page.ID, At: at}); err != nil {
+		return err
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgReminderSet, at.Format(reminderTimeLayout), loc.String()))
+}
+
+// askTimezone() sends the current time zone of the user and asks for a new one
+func (p *Processor) askTimezone(ctx context.Context, r *request) error {
+	loc, err := p.location(ctx, r.userID)
+	if err != nil {
+		return errhandling.Wrap("can't ask timezone", err)
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgEnterTimezone, loc.String()))
+}
+
+// setTimezone() saves the time zone entered by the user.
+// If the zone is unknown, the operation waits for another answer
+func (p *Processor) setTimezone(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't set timezone", err) }()
+
+	loc, err := when.Location(r.text)
+	if err != nil {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgUnknownTimezone))
+	}
+
+	if _, err := p.states.Fire(r.session(), r.input, ""); err != nil {
+		return err
+	}
+
+	if err := p.storage.SetTimezone(ctx, r.userID, loc.String()); err != nil {
+		return err
+	}
+
+	now := p.clock.Now().In(loc).Format(reminderTimeLayout)
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgTimezoneChanged, loc.String(), now))
+}
+
+// SendReminders() sends the links whose reminders are due. It is run by the scheduler.
+// Reminders are kept in the storage, so the ones that came due while the bot
+// was stopped are sent after the restart. A reminder that can't be sent is tried again
+// on the next run until it expires
+func (p *Processor) SendReminders(ctx context.Context, now time.Time) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't send reminders", err) }()
+
+	reminders, err := p.storage.GetDueReminders(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		if now.Sub(reminder.At) > reminderExpiry {
+			if err := p.storage.RemoveReminder(ctx, reminder.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		page, err := p.storage.GetPage(ctx, reminder.UserID, reminder.PageID)
+		if errors.Is(err, storage.ErrPageNotFound) {
+			// Ссылку удалили после того, как назначили напоминание
+			if err := p.storage.RemoveReminder(ctx, reminder.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := p.loadLanguage(ctx, reminder.UserID, ""); err != nil {
+			return err
+		}
+
+		// В личном чате id чата совпадает с id пользователя, ссылки группы принадлежат чату группы
+		text := p.text(reminder.UserID, msgReminder, page.URL) + plainNote(page)
+		if err := p.tg.SendKeyboard(reminder.UserID, text, "", p.pageActionsKeyboard(page)); err != nil {
+			log.Printf("can't send reminder '%d': %s", reminder.ID, err)
+			continue
+		}
+
+		if err := p.storage.RemoveReminder(ctx, reminder.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+)
+
+// request is a message or a button press passed through the middleware to the handler
+type request struct {
+	text     string
+	args     string // Текст после команды: "/join <code>"
+	chatID   int
+	userID   int // Владелец ссылок: пользователь или группа
+	senderID int // Автор сообщения или нажатия
+	group    bool
+	links    []string      // Ссылки, которые можно сохранить из сообщения
+	input    fsm.Input     // Вид сообщения: текст или файл
+	message  *Meta         // Для сообщений
+	callback *CallbackMeta // Для нажатий на кнопки
+	inline   *InlineMeta   // Для inline запросов "@bot query"
+}
+
+// sessionKey identifies the operation in the state machine. In a group the operation belongs
+// to the member who has started it, messages of other members aren't its input
+type sessionKey struct {
+	chatID   int
+	senderID int
+}
+
+func (r *request) session() sessionKey {
+	return sessionKey{chatID: r.chatID, senderID: r.senderID}
+}
+
+type handler func(ctx context.Context, r *request) error
+
+// middleware wraps the handler of the route. The route is the command name
+// or the name of the input that is being handled
+type middleware func(route string, next handler) handler
+
+// Названия маршрутов, которые не являются командами
+const (
+	routeLinks      = "links"
+	routeUnknown    = "unknown"
+	routeUnexpected = "unexpected"
+	routeCallback   = "callback"
+	routeInline     = "inline"
+)
+
+// router chooses the handler for a message. Commands are handled when no operation is in progress,
+// inputs get messages during the step of the operation they are registered for
+type router struct {
+	global     map[string]handler // Команды, доступные во время любой операции
+	commands   map[string]handler
+	inputs     map[fsm.State]handler // Ключ - текущий шаг операции пользователя
+	links      handler
+	unknown    handler // Неизвестная команда вне операции
+	unexpected handler // Сообщение, которого не ждет текущая операция
+	middleware []middleware
+	states     *fsm.Machine[sessionKey]
+}
+
+func newRouter(states *fsm.Machine[sessionKey], middleware ...middleware) *router {
+	return &router{
+		global:     make(map[string]handler),
+		commands:   make(map[string]handler),
+		inputs:     make(map[fsm.State]handler),
+		middleware: middleware,
+		states:     states,
+	}
+}
+
+// route() returns the handler for the request wrapped in the middleware
+func (rt *router) route(r *request) handler {
+	name, h := rt.match(r)
+
+	return rt.wrap(name, h)
+}
+
+func (rt *router) match(r *request) (string, handler) {
+	if h, ok := rt.global[r.text]; ok {
+		return r.text, h
+	}
+
+	if state, _ := rt.states.State(r.session()); state != stateIdle {
+		// Шаг операции принимает только тот ввод, который разрешен таблицей переходов
+		if h, ok := rt.inputs[state]; ok && rt.states.Can(r.session(), r.input) {
+			return string(state), h
+		}
+		return routeUnexpected, rt.unexpected
+	}
+
+	if len(r.links) > 0 {
+		return routeLinks, rt.links
+	}
+	name, args, _ := strings.Cut(r.text, " ")
+	if h, ok := rt.commands[name]; ok {
+		r.args = strings.TrimSpace(args)
+		return name, h
+	}
+
+	return routeUnknown, rt.unknown
+}
+
+// wrap() applies the middleware, the first one is the outermost
+func (rt *router) wrap(route string, h handler) handler {
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		h = rt.middleware[i](route, h)
+	}
+
+	return h
+}
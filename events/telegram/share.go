@@ -0,0 +1,281 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Код приглашения: 10 случайных байт, 16 символов base32
+const inviteCodeBytes = 10
+
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var roleNames = map[storage.Role]msgKey{
+	storage.RoleViewer:      roleViewer,
+	storage.RoleContributor: roleContributor,
+	storage.RoleOwner:       roleOwner,
+}
+
+// Роль, которая нужна, чтобы выбрать общую папку на шаге операции.
+// Операции, которых нет в списке, работают только со своими папками
+var sharedRoles = map[string]storage.Role{
+	SaveLinkCmd: storage.RoleContributor,
+	ShareCmd:    storage.RoleOwner,
+}
+
+// rolesKeyboard() returns one button for each role of the invited users
+func (p *Processor) rolesKeyboard(userID int) [][]tgClient.InlineKeyboardButton {
+	return [][]tgClient.InlineKeyboardButton{
+		{{Text: p.text(userID, btnRoleViewer), CallbackData: strconv.Itoa(int(storage.RoleViewer))}},
+		{{Text: p.text(userID, btnRoleContributor), CallbackData: strconv.Itoa(int(storage.RoleContributor))}},
+		{{Text: p.text(userID, btnRoleOwner), CallbackData: strconv.Itoa(int(storage.RoleOwner))}},
+	}
+}
+
+// sharedData() returns callback data of the shared folder button, "/shared <id>".
+// Names of folders never start with "/", so the data can't be confused with a folder
+func sharedData(id int) string {
+	return SharedCmd + " " + strconv.Itoa(id)
+}
+
+// sharedFolderID() parses callback data of the shared folder button
+func sharedFolderID(data string) (int, bool) {
+	arg, ok := strings.CutPrefix(data, SharedCmd+" ")
+	if !ok {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// sharedButtons() returns buttons of the shared folders where the user has at least the role
+func (p *Processor) sharedButtons(ctx context.Context, userID int, role storage.Role) ([][]tgClient.InlineKeyboardButton, error) {
+	if role == 0 {
+		return nil, nil
+	}
+
+	folders, err := p.storage.GetSharedFolders(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(folders))
+	for _, folder := range folders {
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: btnShared + folder.Folder, CallbackData: sharedData(folder.ID)}})
+	}
+
+	return buttons, nil
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return inviteEncoding.EncodeToString(b), nil
+}
+
+func (p *Processor) chooseRole(meta *CallbackMeta) error {
+	return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgChooseRole), p.rolesKeyboard(meta.UserID))
+}
+
+// createInvite() creates the invite code to the folder chosen on the previous step.
+// The folder is either the folder of the user or a shared folder where he is an owner
+func (p *Processor) createInvite(ctx context.Context, meta *CallbackMeta, target string, rawRole string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't create invite", err) }()
+
+	n, err := strconv.Atoi(rawRole)
+	role := storage.Role(n)
+	if _, ok := roleNames[role]; err != nil || !ok {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgUnexpectedCommand), nil)
+	}
+
+	folder := storage.SharedFolder{OwnerID: meta.UserID, Folder: target}
+	if id, ok := sharedFolderID(target); ok {
+		shared, err := p.storage.GetSharedFolder(ctx, meta.UserID, id)
+		if errors.Is(err, storage.ErrAccessDenied) {
+			return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgAccessDenied), nil)
+		}
+		if err != nil {
+			return err
+		}
+		folder = *shared
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return err
+	}
+
+	err = p.storage.CreateInvite(ctx, meta.UserID, folder, role, code)
+	if errors.Is(err, storage.ErrAccessDenied) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgAccessDenied), nil)
+	}
+	if errors.Is(err, storage.ErrFolderNotFound) {
+		return p.show(meta.ChatID, meta.MessageID, p.text(meta.UserID, msgFolderNotExists), nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.show(meta.ChatID, meta.MessageID,
+		p.text(meta.UserID, msgInviteCreated, folder.Folder, p.text(meta.UserID, roleNames[role]), code), nil)
+}
+
+// joinFolder() opens the folder from the invite to the user and tells the owner about the new member
+func (p *Processor) joinFolder(ctx context.Context, chatID int, userID int, name string, code string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't join folder", err) }()
+
+	if code == "" {
+		return p.tg.SendMessage(chatID, p.text(userID, msgJoinUsage))
+	}
+
+	folder, err := p.storage.JoinFolder(ctx, userID, strings.ToUpper(code))
+	if errors.Is(err, storage.ErrInviteNotFound) {
+		return p.tg.SendMessage(chatID, p.text(userID, msgInviteNotFound))
+	}
+	if err != nil {
+		return err
+	}
+
+	if folder.OwnerID == userID {
+		return p.tg.SendMessage(chatID, p.text(userID, msgOwnFolder))
+	}
+
+	if err := p.tg.SendMessage(chatID, p.text(userID, msgJoined, folder.Folder, p.text(userID, roleNames[folder.Role]))); err != nil {
+		return err
+	}
+
+	p.notify(ctx, folder.OwnerID, func(to int) string {
+		return p.text(to, msgMemberJoined, name, folder.Folder, p.text(to, roleNames[folder.Role]))
+	})
+
+	return nil
+}
+
+// sendSharedFolders() sends the list of folders shared with the user
+func (p *Processor) sendSharedFolders(ctx context.Context, chatID int, messageID int, userID int, page int) (err error) {
+	defer func() {
+		if err != ErrNoFolders {
+			err = errhandling.WrapIfErr("can't send shared folders", err)
+		}
+	}()
+
+	folders, err := p.storage.GetSharedFolders(ctx, userID, storage.RoleViewer)
+	if err != nil {
+		return err
+	}
+	if len(folders) == 0 {
+		_ = p.show(chatID, messageID, p.text(userID, msgNoSharedFolders), nil)
+		return ErrNoFolders
+	}
+
+	buttons := make([][]tgClient.InlineKeyboardButton, 0, len(folders))
+	for _, folder := range folders {
+		text := btnShared + folder.Folder + " (" + p.text(userID, roleNames[folder.Role]) + ")"
+		buttons = append(buttons, []tgClient.InlineKeyboardButton{{Text: text, CallbackData: sharedData(folder.ID)}})
+	}
+
+	pg := newPagination(page, p.pageSize, len(buttons))
+	from, to := pg.bounds(len(buttons))
+
+	return p.show(chatID, messageID, p.text(userID, msgChooseFolder), pg.withPages(buttons[from:to], pageData))
+}
+
+// showShared() shows the links of the shared folder, id identifies the membership of the user
+func (p *Processor) showShared(ctx context.Context, chatID int, messageID int, userID int, id int, page int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't show shared folder", err) }()
+
+	folder, err := p.storage.GetSharedFolder(ctx, userID, id)
+	if errors.Is(err, storage.ErrAccessDenied) {
+		return p.show(chatID, messageID, p.text(userID, msgAccessDenied), nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	count, err := p.storage.CountSharedPages(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return p.show(chatID, messageID, p.text(userID, msgEmptyFolder), nil)
+	}
+
+	pg := newPagination(page, p.pageSize, count)
+
+	pages, err := p.storage.GetSharedPages(ctx, userID, id, pg.size, pg.offset())
+	if err != nil {
+		return err
+	}
+
+	return p.showLinks(chatID, messageID, userID, btnShared+folder.Folder, pages, pg, sharedPageData(id))
+}
+
+// sharedTarget() returns the owner and the path of the folder chosen to save links.
+// For a shared folder the membership of the user is checked
+func (p *Processor) sharedTarget(ctx context.Context, userID int, target string) (ownerID int, folder string, err error) {
+	id, ok := sharedFolderID(target)
+	if !ok {
+		return userID, target, nil
+	}
+
+	shared, err := p.storage.GetSharedFolder(ctx, userID, id)
+	if err != nil {
+		return 0, "", err
+	}
+	if shared.Role < storage.RoleContributor {
+		return 0, "", storage.ErrAccessDenied
+	}
+
+	return shared.OwnerID, shared.Folder, nil
+}
+
+// notifyMembers() tells the owner and the members of the folder, except the user
+// who has added them, about the new links
+func (p *Processor) notifyMembers(ctx context.Context, userID int, name string, ownerID int, folder string, links []string) error {
+	members, err := p.storage.GetMembers(ctx, ownerID, folder)
+	if err != nil {
+		return errhandling.Wrap("can't notify members", err)
+	}
+
+	for _, member := range append(members, ownerID) {
+		if member == userID {
+			continue
+		}
+		p.notify(ctx, member, func(to int) string {
+			return p.text(to, msgLinksAdded, name, folder, strings.Join(links, "\n"))
+		})
+	}
+
+	return nil
+}
+
+// notify() sends the message in the language of the recipient. Notifications aren't
+// the answer to the user's request, so errors (e.g. the recipient blocked the bot) are only logged
+func (p *Processor) notify(ctx context.Context, to int, text func(to int) string) {
+	if err := p.loadLanguage(ctx, to, ""); err != nil {
+		log.Printf("can't notify '%d': %s", to, err)
+		return
+	}
+
+	// В личном чате с ботом id чата совпадает с id пользователя
+	if err := p.tg.SendMessage(to, text(to)); err != nil {
+		log.Printf("can't notify '%d': %s", to, err)
+	}
+}
@@ -0,0 +1,149 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const (
+	snapshotBatchSize     = 5              // Повторных попыток за один запуск, страницы скачиваются по очереди
+	snapshotRetryInterval = 24 * time.Hour // Через сколько снова пробовать скачать страницу, которая не открылась
+)
+
+// snapshotButton() returns the button that sends the saved copy of the page
+func (p *Processor) snapshotButton(page *storage.Page) tgClient.InlineKeyboardButton {
+	return tgClient.InlineKeyboardButton{Text: p.text(page.UserID, btnSnapshot), CallbackData: pageActionData(SnapshotCmd, page.ID)}
+}
+
+// toggleSnapshots() turns on or off saving copies of the pages
+func (p *Processor) toggleSnapshots(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't toggle snapshots", err) }()
+
+	enabled, err := p.storage.IsSnapshotsEnabled(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+
+	if err := p.storage.SetSnapshots(ctx, r.userID, !enabled); err != nil {
+		return err
+	}
+
+	if enabled {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgSnapshotsOff))
+	}
+
+	return p.tg.SendMessage(r.chatID, p.text(r.userID, msgSnapshotsOn))
+}
+
+// sendSnapshot() sends the saved copy of the page by the button under the link
+func (p *Processor) sendSnapshot(ctx context.Context, meta *CallbackMeta, id int) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't send snapshot", err) }()
+
+	page, err := p.storage.GetPage(ctx, meta.UserID, id)
+	if errors.Is(err, storage.ErrPageNotFound) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgPageNotFound))
+	}
+	if err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+
+	snapshot, err := p.storage.GetSnapshot(ctx, page.URL)
+	if errors.Is(err, storage.ErrNoSnapshot) {
+		return p.tg.AnswerCallbackQueryWithText(meta.QueryID, p.text(meta.UserID, msgNoSnapshot))
+	}
+	if err != nil {
+		_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+		return err
+	}
+	_ = p.tg.AnswerCallbackQuery(meta.QueryID)
+
+	loc, err := p.location(ctx, meta.UserID)
+	if err != nil {
+		return err
+	}
+
+	header := p.text(meta.UserID, msgSnapshot, page.URL, snapshot.Created.In(loc).Format(reminderTimeLayout))
+	if snapshot.Title != "" {
+		header += "\n\n" + snapshot.Title
+	}
+
+	items := []string{header + "\n\n"}
+	for _, paragraph := range strings.Split(snapshot.Text, "\n\n") {
+		items = append(items, paragraph+"\n\n")
+	}
+
+	return p.tg.SendItems(meta.ChatID, items, "")
+}
+
+// snapshotLinks() starts downloading copies of the just saved links if the owner has turned
+// snapshots on. The links are downloaded in the background, the ones that already have a copy are skipped
+func (p *Processor) snapshotLinks(ctx context.Context, ownerID int, urls []string) error {
+	enabled, err := p.storage.IsSnapshotsEnabled(ctx, ownerID)
+	if err != nil || !enabled {
+		return errhandling.WrapIfErr("can't snapshot links", err)
+	}
+
+	go func() {
+		ctx := context.Background()
+
+		for _, url := range urls {
+			if _, err := p.storage.GetSnapshot(ctx, url); err == nil {
+				continue
+			}
+			if err := p.saveSnapshot(ctx, url, p.clock.Now()); err != nil {
+				log.Printf("[ERR] %s", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// SaveSnapshots() downloads again the pages that couldn't be downloaded when they were saved.
+// It is run by the scheduler, a page is tried once in snapshotRetryInterval
+func (p *Processor) SaveSnapshots(ctx context.Context, now time.Time) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't save snapshots", err) }()
+
+	if err := p.storage.RemoveUnusedSnapshots(ctx); err != nil {
+		return err
+	}
+
+	urls, err := p.storage.GetURLsToSnapshot(ctx, now.Add(-snapshotRetryInterval), snapshotBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		if err := p.saveSnapshot(ctx, url, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveSnapshot() downloads the page and saves its copy. A page that couldn't be downloaded
+// is saved without text, so it is tried again later
+func (p *Processor) saveSnapshot(ctx context.Context, url string, now time.Time) error {
+	snapshot := &storage.Snapshot{URL: url, Created: now}
+
+	page, err := p.fetcher.Fetch(ctx, url)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		log.Printf("can't save snapshot of '%s': %s", url, err)
+	} else {
+		snapshot.Title, snapshot.Text = page.Title, page.Text
+	}
+
+	return p.storage.SaveSnapshot(ctx, snapshot)
+}
@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+)
+
+// Состояние диалога называется так же, как команда, которая начинает этот шаг
+const stateIdle fsm.State = ""
+
+// Вводы пользователя, кроме команд. Команда, начинающая операцию, сама является вводом
+const (
+	inputText     fsm.Input = "text"
+	inputDocument fsm.Input = "document"
+	inputLinks    fsm.Input = "links"    // Сообщение со ссылками для сохранения
+	inputButton   fsm.Input = "button"   // Нажатие кнопки с папкой, ссылкой или вариантом ответа
+	inputNavigate fsm.Input = "navigate" // Переход по дереву папок
+	inputToggle   fsm.Input = "toggle"   // Выбор варианта, после которого можно выбрать еще один
+)
+
+// Незавершенная операция сбрасывается, если пользователь не продолжил ее за это время
+const operationTimeout = 30 * time.Minute
+
+var transitions = []fsm.Transition{
+	start(ShowFolderCmd),
+	step(ShowFolderCmd, inputNavigate, ShowFolderCmd),
+	step(ShowFolderCmd, inputButton, ""),
+
+	step("", inputLinks, SaveLinkCmd),
+	step(SaveLinkCmd, inputButton, ""),
+
+	start(CreateFolderCmd),
+	step(CreateFolderCmd, inputText, ""),
+
+	start(ChooseFolderForRenaming),
+	step(ChooseFolderForRenaming, inputButton, RenameFolderCmd),
+	step(RenameFolderCmd, inputText, ""),
+
+	start(MoveFolderCmd),
+	step(MoveFolderCmd, inputButton, MoveFolderToCmd),
+	step(MoveFolderToCmd, inputButton, ""),
+
+	start(DeleteFolderCmd),
+	step(DeleteFolderCmd, inputButton, ""),
+
+	start(ChooseLinkForDeletionCmd),
+	step(ChooseLinkForDeletionCmd, inputButton, DeleteLinkCmd),
+	step(DeleteLinkCmd, inputButton, ""),
+
+	start(ExportCmd),
+	step(ExportCmd, inputButton, ""),
+
+	start(ImportCmd),
+	step(ImportCmd, inputDocument, ""),
+
+	start(ShareCmd),
+	step(ShareCmd, inputButton, ShareRoleCmd),
+	step(ShareRoleCmd, inputButton, ""),
+
+	start(SharedCmd),
+	step(SharedCmd, inputButton, ""),
+
+	// Начинается кнопкой под ссылкой
+	start(NoteCmd),
+	step(NoteCmd, inputText, ""),
+
+	start(RemindCmd),
+	step(RemindCmd, inputButton, RemindLinkCmd),
+	step(RemindLinkCmd, inputButton, RemindTimeCmd),
+
+	// Начинается и командой /remind, и кнопкой под ссылкой
+	start(RemindTimeCmd),
+	step(RemindTimeCmd, inputText, ""),
+
+	start(TimezoneCmd),
+	step(TimezoneCmd, inputText, ""),
+
+	start(DigestCmd),
+	step(DigestCmd, inputButton, DigestHourCmd),
+	step(DigestHourCmd, inputButton, DigestFoldersCmd),
+	step(DigestFoldersCmd, inputToggle, DigestFoldersCmd),
+	step(DigestFoldersCmd, inputButton, ""),
+
+	start(DeadCmd),
+	step(DeadCmd, inputToggle, DeadCmd),
+	step(DeadCmd, inputButton, ""),
+
+	start(LanguageCmd),
+	step(LanguageCmd, inputButton, ""),
+}
+
+// start() returns the transition from the idle state by the command that starts the operation
+func start(cmd string) fsm.Transition {
+	return step("", fsm.Input(cmd), cmd)
+}
+
+// step() returns the transition between operations, "" is the idle state
+func step(from string, input fsm.Input, to string) fsm.Transition {
+	return fsm.Transition{From: fsm.State(from), Input: input, To: fsm.State(to)}
+}
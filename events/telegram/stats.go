@@ -0,0 +1,153 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const (
+	statsWeeks       = 8  // За сколько последних недель показывается число сохраненных ссылок
+	statsFolders     = 10 // Самые большие папки, остальные только считаются
+	statsDomains     = 5
+	statsBarWidth    = 10 // Длина полосы недели с наибольшим числом ссылок
+	statsBar         = "▇"
+	statsWeekLayout  = "02.01"
+	statsDateLayout  = "02.01.2006"
+	statsCountFormat = " — "
+)
+
+// sendStats() sends the statistics of the user's links: folders, activity by week,
+// read and unread links, favorite sites and the link that waits for reading the longest
+func (p *Processor) sendStats(ctx context.Context, r *request) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't send stats", err) }()
+
+	byStatus, err := p.storage.CountPagesByStatus(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, count := range byStatus {
+		total += count
+	}
+	if total == 0 {
+		return p.tg.SendMessage(r.chatID, p.text(r.userID, msgNoSavedPages))
+	}
+
+	items := []string{p.text(r.userID, msgStats, total, byStatus[storage.StatusUnread],
+		byStatus[storage.StatusRead], byStatus[storage.StatusArchived]) + "\n\n"}
+
+	folders, err := p.statsFolders(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+	items = append(items, folders...)
+
+	weeks, err := p.statsWeeks(ctx, r.userID)
+	if err != nil {
+		return err
+	}
+	items = append(items, weeks...)
+
+	domains, err := p.storage.TopDomains(ctx, r.userID, statsDomains)
+	if err != nil {
+		return err
+	}
+	items = append(items, p.text(r.userID, msgStatsDomains)+"\n")
+	for i, domain := range domains {
+		items = append(items, strconv.Itoa(i+1)+". "+domain.Name+statsCountFormat+strconv.Itoa(domain.Count)+"\n")
+	}
+
+	oldest, err := p.storage.GetOldestUnread(ctx, r.userID)
+	if err != nil && !errors.Is(err, storage.ErrNoSavedPages) {
+		return err
+	}
+	if err == nil {
+		oldestText, err := p.statsOldest(ctx, r.userID, oldest)
+		if err != nil {
+			return err
+		}
+		items = append(items, "\n"+oldestText)
+	}
+
+	return p.tg.SendItems(r.chatID, items, "")
+}
+
+// statsFolders() returns the lines about the largest folders
+func (p *Processor) statsFolders(ctx context.Context, userID int) ([]string, error) {
+	folders, err := p.storage.CountPagesByFolder(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{p.text(userID, msgStatsFolders) + "\n"}
+	for i, folder := range folders {
+		if i == statsFolders {
+			lines = append(lines, p.text(userID, msgStatsMore, len(folders)-statsFolders)+"\n")
+			break
+		}
+		lines = append(lines, folder.Name+statsCountFormat+strconv.Itoa(folder.Count)+"\n")
+	}
+
+	return append(lines, "\n"), nil
+}
+
+// statsWeeks() returns a bar for each of the last weeks. Weeks start on Monday in the user's time zone
+func (p *Processor) statsWeeks(ctx context.Context, userID int) ([]string, error) {
+	loc, err := p.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.clock.Now().In(loc)
+	monday := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	since := monday.AddDate(0, 0, -7*(statsWeeks-1))
+
+	counts, err := p.storage.CountAddedByWeek(ctx, userID, since, statsWeeks)
+	if err != nil {
+		return nil, err
+	}
+
+	highest := 0
+	for _, count := range counts {
+		if count > highest {
+			highest = count
+		}
+	}
+
+	lines := []string{p.text(userID, msgStatsWeeks) + "\n"}
+	for i, count := range counts {
+		width := 0
+		if highest > 0 {
+			width = count * statsBarWidth / highest
+		}
+		if width == 0 && count > 0 {
+			width = 1
+		}
+
+		start := since.AddDate(0, 0, 7*i).Format(statsWeekLayout)
+		lines = append(lines, start+" "+strings.Repeat(statsBar, width)+" "+strconv.Itoa(count)+"\n")
+	}
+
+	return append(lines, "\n"), nil
+}
+
+// statsOldest() describes the oldest unread link. Links saved by old versions have no date
+func (p *Processor) statsOldest(ctx context.Context, userID int, page *storage.Page) (string, error) {
+	if page.Created.IsZero() {
+		return p.text(userID, msgStatsOldestUndated, page.URL), nil
+	}
+
+	loc, err := p.location(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return p.text(userID, msgStatsOldest, page.Created.In(loc).Format(statsDateLayout), page.URL), nil
+}
@@ -1,46 +1,90 @@
 package telegram
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"sync"
 
 	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
 	"github.com/hahaclassic/golang-telegram-bot.git/events"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/fsm"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/i18n"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/linkcheck"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/ratelimit"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/snapshot"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
 	"github.com/hahaclassic/golang-telegram-bot.git/storage"
 )
 
 // Данный тип реализует сразу два интерфейса: Processor() и Fetcher()
 type Processor struct {
-	tg       *tgClient.Client
-	offset   int
-	storage  storage.Storage
-	sessions map[int]Session
+	tg        *tgClient.Client
+	offset    int
+	storage   storage.Storage
+	states    *fsm.Machine[sessionKey] // Шаг многошаговой операции каждого пользователя в каждом чате
+	pageSize  int
+	links     urlnorm.Detector
+	mu        sync.Mutex        // Защищает languages: напоминания отправляются из планировщика
+	languages map[int]i18n.Lang // Язык каждого пользователя, чтобы не читать его из хранилища на каждое сообщение
+	router    *router
+	limiter   *ratelimit.Limiter
+	allowed   map[int]bool // Пользователи, которым доступен бот. Пустая карта - доступен всем
+	username  string       // Имя бота, к которому обращаются команды в группах: /show@username
+	clock     clock.Clock
+	checker   *linkcheck.Checker
+	fetcher   *snapshot.Fetcher
 }
 
-// Для асинхронной обработки будет добавлена карта с сессиями, где ключом является userID
-// При статусе ОК сессия будет удалятся из карты. (Возможно, будет удаляется через некоторое время)
-type Session struct {
-	lastMessage      string
-	currentOperation string
-	status           bool
+type Config struct {
+	PageSize          int         // Количество кнопок или ссылок на одной странице длинного списка
+	AllowPrivateHosts bool        // Разрешить сохранять ссылки на localhost и адреса внутренних сетей
+	RateLimit         float64     // Запросов в секунду от одного пользователя, 0 - без ограничения
+	RateBurst         int         // Сколько запросов подряд можно отправить без ожидания
+	AllowedUsers      []int       // Если список не пуст, бот отвечает только этим пользователям
+	Clock             clock.Clock // Часы, по которым назначаются напоминания. nil - системные часы
+	CheckConcurrency  int         // Сколько ссылок проверяется одновременно при поиске битых ссылок
 }
 
+// В группах ссылки и папки принадлежат чату: UserID - это id чата, а SenderID - автор сообщения.
+// В личном чате оба поля равны id пользователя
 type Meta struct {
-	ChatID int
-	UserID int
+	ChatID   int
+	UserID   int
+	SenderID int
+	Group    bool
+	URLs     []string // Ссылки из сущностей сообщения, в том числе спрятанные под текстом
+	Source   string   // Ссылка на пост канала, если сообщение переслано
+	Document *tgClient.Document
+	Language string // Язык интерфейса Telegram, используется, пока пользователь не выбрал язык бота
+	Name     string // Имя пользователя для уведомлений участникам общих папок
+}
+
+type InlineMeta struct {
+	QueryID  string
+	UserID   int
+	Offset   string
+	Language string
 }
 
 type CallbackMeta struct {
-	QueryID string
-	UserID  int
-	Message string
-	ChatID  int
+	QueryID   string
+	UserID    int
+	SenderID  int
+	Group     bool
+	Message   string
+	ChatID    int
+	MessageID int                               // Сообщение с клавиатурой, на которой нажата кнопка
+	Keyboard  [][]tgClient.InlineKeyboardButton // Текущая клавиатура этого сообщения
+	Language  string
+	Name      string
 }
 
-const (
-	statusOK         = true
-	statusProcessing = false
-)
+func (m *CallbackMeta) session() sessionKey {
+	return sessionKey{chatID: m.ChatID, senderID: m.SenderID}
+}
 
 var (
 	ErrUnknownEvent    = errors.New("unknown event type")
@@ -49,12 +93,32 @@ var (
 	ErrEmptyFolder     = errors.New("Empty folder")
 )
 
-func New(client *tgClient.Client, storage storage.Storage) *Processor {
-	return &Processor{
-		tg:       client,
-		storage:  storage,
-		sessions: make(map[int]Session),
+func New(client *tgClient.Client, storage storage.Storage, cfg Config) *Processor {
+	p := &Processor{
+		tg:        client,
+		storage:   storage,
+		states:    fsm.New[sessionKey](stateIdle, operationTimeout, transitions...),
+		pageSize:  cfg.PageSize,
+		links:     urlnorm.Detector{AllowPrivateHosts: cfg.AllowPrivateHosts},
+		languages: make(map[int]i18n.Lang),
+		limiter:   ratelimit.New(cfg.RateLimit, cfg.RateBurst),
+		allowed:   make(map[int]bool, len(cfg.AllowedUsers)),
+		clock:     cfg.Clock,
+		checker:   linkcheck.New(cfg.CheckConcurrency, cfg.AllowPrivateHosts),
+		fetcher:   snapshot.New(cfg.AllowPrivateHosts),
 	}
+
+	if p.clock == nil {
+		p.clock = clock.Real
+	}
+	p.states.WithClock(p.clock)
+
+	for _, userID := range cfg.AllowedUsers {
+		p.allowed[userID] = true
+	}
+	p.router = p.routes()
+
+	return p
 }
 
 func (p *Processor) Fetch(limit int) ([]events.Event, error) {
@@ -84,6 +148,8 @@ func (p *Processor) Process(event events.Event) error {
 		return p.processMessage(event)
 	case events.CallbackQuery:
 		return p.processCallbackQuery(event)
+	case events.InlineQuery:
+		return p.processInlineQuery(event)
 	default:
 		return errhandling.Wrap("can't process the message", ErrUnknownEvent)
 	}
@@ -97,23 +163,77 @@ func (p *Processor) processCallbackQuery(event events.Event) (err error) {
 		return err
 	}
 
-	if _, ok := p.sessions[meta.UserID]; !ok {
-		p.sessions[meta.UserID] = Session{
-			status: statusOK,
+	if err := p.loadLanguage(context.Background(), meta.UserID, meta.Language); err != nil {
+		return err
+	}
+
+	r := &request{
+		text:     strings.TrimSpace(event.Text),
+		chatID:   meta.ChatID,
+		userID:   meta.UserID,
+		senderID: meta.SenderID,
+		group:    meta.Group,
+		callback: &meta,
+	}
+
+	return p.router.wrap(routeCallback, p.handleCallback)(context.Background(), r)
+}
+
+// handleCallback() handles the button press. Buttons under links and pages of folders
+// work whatever operation is in progress, other buttons belong to the current operation
+func (p *Processor) handleCallback(ctx context.Context, r *request) error {
+	meta := r.callback
+
+	if r.text == PageIndicatorCmd {
+		return p.tg.AnswerCallbackQuery(meta.QueryID)
+	}
+	if cmd, id, ok := pageAction(r.text); ok {
+		switch cmd {
+		case NoteCmd:
+			return p.askNote(ctx, meta, id)
+		case RemindTimeCmd:
+			return p.askRemindTime(ctx, meta, id)
+		case SnapshotCmd:
+			return p.sendSnapshot(ctx, meta, id)
 		}
-	} else if p.sessions[meta.UserID].status == statusOK {
-		delete(p.sessions, meta.UserID)
+		return p.changeStatus(ctx, meta, cmd, id)
 	}
+	if folderID, page, ok := folderPage(r.text); ok {
+		defer func() { _ = p.tg.AnswerCallbackQuery(meta.QueryID) }()
+		return p.turnFolderPage(ctx, meta, folderID, page)
+	}
+	if id, page, ok := sharedPage(r.text); ok {
+		defer func() { _ = p.tg.AnswerCallbackQuery(meta.QueryID) }()
+		return p.showShared(ctx, meta.ChatID, meta.MessageID, meta.UserID, id, page)
+	}
+	if page, ok := unreadPage(r.text); ok {
+		defer func() { _ = p.tg.AnswerCallbackQuery(meta.QueryID) }()
+		return p.sendUnread(ctx, meta.ChatID, meta.MessageID, meta.UserID, page)
+	}
+
+	return p.doCallbackCmd(r.text, meta)
+}
 
-	if err := p.doCallbackCmd(event.Text, &meta); err != nil {
+func (p *Processor) processInlineQuery(event events.Event) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't process inline query", err) }()
+
+	meta, err := inlineMeta(event)
+	if err != nil {
 		return err
 	}
 
-	if p.sessions[meta.UserID].status == statusOK {
-		delete(p.sessions, meta.UserID)
+	if err := p.loadLanguage(context.Background(), meta.UserID, meta.Language); err != nil {
+		return err
 	}
 
-	return nil
+	r := &request{
+		text:     strings.TrimSpace(event.Text),
+		userID:   meta.UserID,
+		senderID: meta.UserID,
+		inline:   &meta,
+	}
+
+	return p.router.wrap(routeInline, p.searchInline)(context.Background(), r)
 }
 
 func (p *Processor) processMessage(event events.Event) (err error) {
@@ -124,25 +244,102 @@ func (p *Processor) processMessage(event events.Event) (err error) {
 		return err
 	}
 
-	if _, ok := p.sessions[meta.UserID]; !ok {
-		p.sessions[meta.UserID] = Session{
-			status: statusOK,
+	if err := p.loadLanguage(context.Background(), meta.UserID, meta.Language); err != nil {
+		return err
+	}
+
+	r := &request{
+		text:     strings.TrimSpace(event.Text),
+		chatID:   meta.ChatID,
+		userID:   meta.UserID,
+		senderID: meta.SenderID,
+		group:    meta.Group,
+		input:    inputText,
+		message:  &meta,
+	}
+	if meta.Document != nil {
+		r.input = inputDocument
+	}
+
+	if meta.Group && !p.addressed(r) {
+		// Остальные сообщения группы адресованы не боту, из них только сохраняются ссылки
+		return p.capture(context.Background(), r)
+	}
+	r.links = p.messageLinks(r.text, &meta)
+
+	if err := p.router.route(r)(context.Background(), r); err != nil {
+		// Операция, на шаге которой произошла ошибка, прерывается
+		p.states.Reset(r.session())
+		if !errors.Is(err, ErrNoFolders) {
+			return err
 		}
 	}
 
-	if err := p.doCmd(event.Text, meta.ChatID, meta.UserID); err != nil {
+	return nil
+}
+
+// loadLanguage() remembers the language of the user. The language chosen with /language
+// is taken from the storage, otherwise the language of the Telegram interface is used
+func (p *Processor) loadLanguage(ctx context.Context, userID int, tag string) error {
+	if _, ok := p.language(userID); ok {
+		return nil
+	}
+
+	saved, err := p.storage.GetLanguage(ctx, userID)
+	if err != nil {
 		return err
 	}
 
-	if p.sessions[meta.UserID].status == statusOK {
-		delete(p.sessions, meta.UserID)
+	lang, ok := i18n.Parse(saved)
+	if !ok {
+		lang, _ = i18n.Parse(tag)
 	}
+	p.setUserLanguage(userID, lang)
 
 	return nil
 }
 
-func (p *Processor) changeSessionData(userID int, new Session) {
-	p.sessions[userID] = new
+// language() returns the remembered language of the user
+func (p *Processor) language(userID int) (i18n.Lang, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lang, ok := p.languages[userID]
+
+	return lang, ok
+}
+
+func (p *Processor) setUserLanguage(userID int, lang i18n.Lang) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.languages[userID] = lang
+}
+
+// text() returns the message in the language of the user
+func (p *Processor) text(userID int, key msgKey, args ...any) string {
+	lang, _ := p.language(userID)
+
+	return messages.Text(lang, key, args...)
+}
+
+// show() sends a new message or, if messageID isn't 0, replaces the message
+// with the keyboard that the user has tapped. Without buttons the keyboard is removed,
+// so the finished operation can't be repeated by tapping the old buttons
+func (p *Processor) show(chatID int, messageID int, text string, buttons [][]tgClient.InlineKeyboardButton) error {
+	return p.showFormatted(chatID, messageID, text, "", buttons)
+}
+
+// showFormatted() works like show() for texts with markup in the given parse mode
+func (p *Processor) showFormatted(chatID int, messageID int, text string, parseMode string, buttons [][]tgClient.InlineKeyboardButton) error {
+	if messageID != 0 {
+		return p.tg.EditMessageText(chatID, messageID, text, parseMode, buttons)
+	}
+	if len(buttons) == 0 {
+		return p.tg.SendFormatted(chatID, text, parseMode)
+	}
+
+	return p.tg.SendKeyboard(chatID, text, parseMode, buttons)
 }
 
 func meta(event events.Event) (Meta, error) {
@@ -163,6 +360,15 @@ func callbackMeta(event events.Event) (CallbackMeta, error) {
 	return res, nil
 }
 
+func inlineMeta(event events.Event) (InlineMeta, error) {
+	res, ok := event.Meta.(InlineMeta)
+	if !ok {
+		return InlineMeta{}, errhandling.Wrap("can't get meta", ErrUnknownMetaType)
+	}
+
+	return res, nil
+}
+
 func event(upd tgClient.Update) events.Event {
 	updType := fetchType(upd)
 
@@ -173,26 +379,60 @@ func event(upd tgClient.Update) events.Event {
 
 	if updType == events.Message {
 		res.Meta = Meta{
-			ChatID: upd.Message.Chat.ID,
-			UserID: upd.Message.From.UserID,
+			ChatID:   upd.Message.Chat.ID,
+			UserID:   owner(upd.Message.Chat, upd.Message.From),
+			SenderID: upd.Message.From.UserID,
+			Group:    upd.Message.Chat.IsGroup(),
+			URLs:     upd.Message.URLs(),
+			Source:   upd.Message.OriginalPostURL(),
+			Document: upd.Message.Document,
+			Language: upd.Message.From.LanguageCode,
+			Name:     upd.Message.From.Name(),
 		}
 	} else if updType == events.CallbackQuery {
-		res.Meta = CallbackMeta{
-			QueryID: upd.CallbackQuery.QueryID,
-			UserID:  upd.CallbackQuery.From.UserID,
-			Message: upd.CallbackQuery.Message.Text,
-			ChatID:  upd.CallbackQuery.Message.Chat.ID,
+		meta := CallbackMeta{
+			QueryID:   upd.CallbackQuery.QueryID,
+			UserID:    owner(upd.CallbackQuery.Message.Chat, upd.CallbackQuery.From),
+			SenderID:  upd.CallbackQuery.From.UserID,
+			Group:     upd.CallbackQuery.Message.Chat.IsGroup(),
+			Message:   upd.CallbackQuery.Message.Text,
+			ChatID:    upd.CallbackQuery.Message.Chat.ID,
+			MessageID: upd.CallbackQuery.Message.MessageID,
+			Language:  upd.CallbackQuery.From.LanguageCode,
+			Name:      upd.CallbackQuery.From.Name(),
+		}
+		if upd.CallbackQuery.Message.ReplyMarkup != nil {
+			meta.Keyboard = upd.CallbackQuery.Message.ReplyMarkup.InlineKeyboard
+		}
+		res.Meta = meta
+	} else if updType == events.InlineQuery {
+		res.Meta = InlineMeta{
+			QueryID:  upd.InlineQuery.ID,
+			UserID:   upd.InlineQuery.From.UserID,
+			Offset:   upd.InlineQuery.Offset,
+			Language: upd.InlineQuery.From.LanguageCode,
 		}
 	}
 
 	return res
 }
 
+// owner() returns the id of the user or the group that owns the links in the chat
+func owner(chat tgClient.Chat, from tgClient.From) int {
+	if chat.IsGroup() {
+		return chat.ID
+	}
+
+	return from.UserID
+}
+
 func fetchText(upd tgClient.Update) string {
 	if upd.Message != nil {
 		return upd.Message.Text
 	} else if upd.CallbackQuery != nil {
 		return upd.CallbackQuery.Data
+	} else if upd.InlineQuery != nil {
+		return upd.InlineQuery.Query
 	}
 
 	return ""
@@ -203,6 +443,8 @@ func fetchType(upd tgClient.Update) events.Type {
 		return events.Message
 	} else if upd.CallbackQuery != nil {
 		return events.CallbackQuery
+	} else if upd.InlineQuery != nil {
+		return events.InlineQuery
 	}
 
 	return events.Unknown
@@ -14,6 +14,7 @@ const (
 	Unknown Type = iota
 	Message
 	CallbackQuery
+	InlineQuery
 )
 
 type Event struct {
@@ -2,4 +2,9 @@ module github.com/hahaclassic/golang-telegram-bot.git
 
 go 1.20
 
-require github.com/mattn/go-sqlite3 v1.14.17
+require (
+	github.com/mattn/go-sqlite3 v1.14.17
+	golang.org/x/net v0.17.0
+)
+
+require golang.org/x/text v0.13.0 // indirect
@@ -0,0 +1,57 @@
+package bookmarks
+
+import (
+	"errors"
+	"time"
+)
+
+// Bookmark is a saved link in a form that doesn't depend on the storage
+type Bookmark struct {
+	URL     string    `json:"url"`
+	Folder  string    `json:"folder"` // Путь папки: "Work/Go/Talks"
+	Title   string    `json:"title,omitempty"`
+	Status  string    `json:"status,omitempty"` // unread, read или archived
+	Source  string    `json:"source,omitempty"` // Ссылка на пост, из которого сохранена ссылка
+	Note    string    `json:"note,omitempty"`
+	AddedAt time.Time `json:"-"`
+}
+
+// jsonBookmark is a Bookmark in the JSON file. The time is a string so that unknown time can be omitted
+type jsonBookmark struct {
+	Bookmark
+	AddedAt string `json:"added_at,omitempty"`
+}
+
+// jsonCollection is a Collection in the JSON file
+type jsonCollection struct {
+	Folders   []string       `json:"folders"`
+	Bookmarks []jsonBookmark `json:"bookmarks"`
+}
+
+// Collection is the contents of an export file. Folders keep empty folders too
+type Collection struct {
+	Folders   []string   `json:"folders"`
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+type Format string
+
+const (
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Markdown Format = "md"
+	Netscape Format = "html" // Формат закладок браузеров
+)
+
+var Formats = []Format{JSON, CSV, Markdown, Netscape}
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// title() returns the title of the bookmark or its URL if there is no title
+func (b Bookmark) title() string {
+	if b.Title != "" {
+		return b.Title
+	}
+
+	return b.URL
+}
@@ -0,0 +1,199 @@
+package bookmarks
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+)
+
+var csvHeader = []string{"url", "folder", "title", "status", "source", "added_at", "note"}
+
+// Export() writes the collection in the given format
+func Export(w io.Writer, c Collection, format Format) error {
+	switch format {
+	case JSON:
+		return exportJSON(w, c)
+	case CSV:
+		return exportCSV(w, c)
+	case Markdown:
+		return exportMarkdown(w, c)
+	case Netscape:
+		return exportNetscape(w, c)
+	}
+
+	return ErrUnknownFormat
+}
+
+func exportJSON(w io.Writer, c Collection) error {
+	file := jsonCollection{Folders: c.Folders, Bookmarks: make([]jsonBookmark, 0, len(c.Bookmarks))}
+	if file.Folders == nil {
+		file.Folders = []string{}
+	}
+	for _, b := range c.Bookmarks {
+		file.Bookmarks = append(file.Bookmarks, jsonBookmark{Bookmark: b, AddedAt: formatTime(b.AddedAt)})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return errhandling.WrapIfErr("can't export json", encoder.Encode(file))
+}
+
+func exportCSV(w io.Writer, c Collection) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return errhandling.Wrap("can't export csv", err)
+	}
+
+	for _, b := range c.Bookmarks {
+		record := []string{b.URL, b.Folder, b.Title, b.Status, b.Source, formatTime(b.AddedAt), b.Note}
+		if err := writer.Write(record); err != nil {
+			return errhandling.Wrap("can't export csv", err)
+		}
+	}
+
+	writer.Flush()
+
+	return errhandling.WrapIfErr("can't export csv", writer.Error())
+}
+
+func exportMarkdown(w io.Writer, c Collection) error {
+	var sb strings.Builder
+
+	sb.WriteString("# Bookmarks\n")
+
+	for _, folder := range sortedFolders(c) {
+		header := "\n## " + markdownEscaper.Replace(folder) + "\n\n"
+		for _, b := range c.Bookmarks {
+			if b.Folder == folder {
+				// Пустые папки в markdown не выводятся
+				sb.WriteString(header)
+				header = ""
+				sb.WriteString("- [" + markdownEscaper.Replace(b.title()) + "](" + markdownURLEscaper.Replace(b.URL) + ")")
+				if b.Note != "" {
+					sb.WriteString(" - " + markdownEscaper.Replace(strings.Join(strings.Fields(b.Note), " ")))
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return errhandling.WrapIfErr("can't export markdown", err)
+}
+
+var (
+	// Экранируются символы, которые меняют вид текста или ломают ссылку
+	markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`,
+		"`", "\\`", "<", `\<`)
+	markdownURLEscaper = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20")
+)
+
+// exportNetscape() writes the Netscape bookmark file that browsers can import.
+// Nested folders become nested <DL> lists
+func exportNetscape(w io.Writer, c Collection) error {
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	sb.WriteString("<!-- This is an automatically generated file. It will be read and overwritten. DO NOT EDIT! -->\n")
+	sb.WriteString(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">` + "\n")
+	sb.WriteString("<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n<DL><p>\n")
+
+	var open []string // Открытые в данный момент папки
+
+	for _, folder := range sortedFolders(c) {
+		// Закрываем папки, которые не являются родителями текущей
+		for len(open) > 0 && !folderpath.IsInside(folder, open[len(open)-1]) {
+			open = open[:len(open)-1]
+			sb.WriteString(indent(len(open)+1) + "</DL><p>\n")
+		}
+
+		// Открываем недостающих родителей и саму папку
+		for _, ancestor := range folderpath.Ancestors(folder)[len(open):] {
+			sb.WriteString(indent(len(open)+1) + "<DT><H3>" + html.EscapeString(folderpath.Base(ancestor)) + "</H3>\n")
+			sb.WriteString(indent(len(open)+1) + "<DL><p>\n")
+			open = append(open, ancestor)
+		}
+
+		for _, b := range c.Bookmarks {
+			if b.Folder != folder {
+				continue
+			}
+			sb.WriteString(indent(len(open)+1) + `<DT><A HREF="` + html.EscapeString(b.URL) + `"`)
+			if !b.AddedAt.IsZero() {
+				sb.WriteString(` ADD_DATE="` + strconv.FormatInt(b.AddedAt.Unix(), 10) + `"`)
+			}
+			sb.WriteString(">" + html.EscapeString(b.title()) + "</A>\n")
+			if b.Note != "" {
+				// Описание закладки в браузерах
+				sb.WriteString(indent(len(open)+1) + "<DD>" + html.EscapeString(b.Note) + "\n")
+			}
+		}
+	}
+
+	for len(open) > 0 {
+		open = open[:len(open)-1]
+		sb.WriteString(indent(len(open)+1) + "</DL><p>\n")
+	}
+	sb.WriteString("</DL><p>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return errhandling.WrapIfErr("can't export bookmarks html", err)
+}
+
+// sortedFolders() returns all folders of the collection, parents go before their subfolders
+func sortedFolders(c Collection) []string {
+	seen := make(map[string]bool)
+	var folders []string
+
+	add := func(folder string) {
+		if folder != "" && !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+
+	for _, folder := range c.Folders {
+		add(folder)
+	}
+	for _, b := range c.Bookmarks {
+		add(b.Folder)
+	}
+
+	// Сравнение по частям пути, чтобы "Work/Go" шла сразу после "Work", а не после "Work Stuff"
+	sort.Slice(folders, func(i, j int) bool {
+		a, b := strings.Split(folders[i], folderpath.Separator), strings.Split(folders[j], folderpath.Separator)
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return folders
+}
+
+func indent(level int) string {
+	return strings.Repeat("    ", level)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,175 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testCollection() has nested folders, an empty folder and titles that need escaping
+func testCollection() Collection {
+	return Collection{
+		Folders: []string{"Work", "Work/Go", "Empty", "Work/Go/Talks"},
+		Bookmarks: []Bookmark{
+			{
+				URL:     "https://go.dev/blog",
+				Folder:  "Work/Go",
+				Title:   "The Go Blog",
+				Status:  "unread",
+				AddedAt: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+			},
+			{
+				URL:    "https://example.com/a?x=1&y=2",
+				Folder: "Work",
+				Title:  "Tags [a] <b> & c",
+				Status: "read",
+				Source: "https://t.me/c/1/2",
+				Note:   "note with *stars* and _under_ <i>",
+			},
+			{URL: "https://example.com/wiki/Go_(language)", Folder: "Work/Go/Talks", Title: "snake_case **bold**"},
+			{URL: "https://example.com/plain", Folder: "Read Later"},
+		},
+	}
+}
+
+const goldenJSON = `{
+  "folders": [
+    "Work",
+    "Work/Go",
+    "Empty",
+    "Work/Go/Talks"
+  ],
+  "bookmarks": [
+    {
+      "url": "https://go.dev/blog",
+      "folder": "Work/Go",
+      "title": "The Go Blog",
+      "status": "unread",
+      "added_at": "2024-03-01T12:30:00Z"
+    },
+    {
+      "url": "https://example.com/a?x=1&y=2",
+      "folder": "Work",
+      "title": "Tags [a] <b> & c",
+      "status": "read",
+      "source": "https://t.me/c/1/2",
+      "note": "note with *stars* and _under_ <i>"
+    },
+    {
+      "url": "https://example.com/wiki/Go_(language)",
+      "folder": "Work/Go/Talks",
+      "title": "snake_case **bold**"
+    },
+    {
+      "url": "https://example.com/plain",
+      "folder": "Read Later"
+    }
+  ]
+}
+`
+
+const goldenCSV = `url,folder,title,status,source,added_at,note
+https://go.dev/blog,Work/Go,The Go Blog,unread,,2024-03-01T12:30:00Z,
+https://example.com/a?x=1&y=2,Work,Tags [a] <b> & c,read,https://t.me/c/1/2,,note with *stars* and _under_ <i>
+https://example.com/wiki/Go_(language),Work/Go/Talks,snake_case **bold**,,,,
+https://example.com/plain,Read Later,,,,,
+`
+
+// Пустая папка в markdown не выводится
+const goldenMarkdown = `# Bookmarks
+
+## Read Later
+
+- [https://example.com/plain](https://example.com/plain)
+
+## Work
+
+- [Tags \[a\] \<b> & c](https://example.com/a?x=1&y=2) - note with \*stars\* and \_under\_ \<i>
+
+## Work/Go
+
+- [The Go Blog](https://go.dev/blog)
+
+## Work/Go/Talks
+
+- [snake\_case \*\*bold\*\*](https://example.com/wiki/Go_%28language%29)
+`
+
+const goldenNetscape = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file. It will be read and overwritten. DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3>Empty</H3>
+    <DL><p>
+    </DL><p>
+    <DT><H3>Read Later</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/plain">https://example.com/plain</A>
+    </DL><p>
+    <DT><H3>Work</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/a?x=1&amp;y=2">Tags [a] &lt;b&gt; &amp; c</A>
+        <DD>note with *stars* and _under_ &lt;i&gt;
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev/blog" ADD_DATE="1709296200">The Go Blog</A>
+            <DT><H3>Talks</H3>
+            <DL><p>
+                <DT><A HREF="https://example.com/wiki/Go_(language)">snake_case **bold**</A>
+            </DL><p>
+        </DL><p>
+    </DL><p>
+</DL><p>
+`
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSON, goldenJSON},
+		{CSV, goldenCSV},
+		{Markdown, goldenMarkdown},
+		{Netscape, goldenNetscape},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, testCollection(), tt.format); err != nil {
+				t.Fatalf("Export(%s) error = %v", tt.format, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Export(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportEmpty(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSON, "{\n  \"folders\": [],\n  \"bookmarks\": []\n}\n"},
+		{CSV, "url,folder,title,status,source,added_at,note\n"},
+		{Markdown, "# Bookmarks\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Export(&buf, Collection{}, tt.format); err != nil {
+			t.Fatalf("Export(%s) error = %v", tt.format, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Export(%s) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	if err := Export(&bytes.Buffer{}, Collection{}, "xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Export(xml) error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
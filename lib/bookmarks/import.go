@@ -0,0 +1,277 @@
+package bookmarks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"golang.org/x/net/html"
+)
+
+var ErrNoBookmarks = errors.New("no bookmarks found")
+
+// Pocket отмечает прочитанные ссылки как "archive", в боте это просто прочитанные ссылки
+const (
+	pocketArchive      = "archive"
+	pocketArchiveTitle = "read archive"
+	statusRead         = "read"
+)
+
+// Import() reads bookmarks from a file. Supported files are the files of Export(),
+// browser bookmarks (Netscape format) and Pocket exports (HTML and CSV).
+// The format is detected by the contents, so the file name doesn't matter
+func Import(data []byte) (c Collection, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't import bookmarks", err) }()
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	trimmed := bytes.TrimSpace(data)
+
+	switch {
+	case len(trimmed) == 0:
+		return Collection{}, ErrNoBookmarks
+	case trimmed[0] == '{':
+		c, err = importJSON(trimmed)
+	case trimmed[0] == '<':
+		c, err = importHTML(trimmed)
+	default:
+		c, err = importCSV(trimmed)
+	}
+	if err != nil {
+		return Collection{}, err
+	}
+
+	if len(c.Bookmarks) == 0 && len(c.Folders) == 0 {
+		return Collection{}, ErrNoBookmarks
+	}
+
+	return c, nil
+}
+
+func importJSON(data []byte) (Collection, error) {
+	var file jsonCollection
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Collection{}, err
+	}
+
+	c := Collection{Folders: file.Folders}
+	for _, b := range file.Bookmarks {
+		b.Bookmark.AddedAt = parseTime(b.AddedAt)
+		c.Bookmarks = append(c.Bookmarks, b.Bookmark)
+	}
+
+	return c, nil
+}
+
+// importCSV() reads the files with a header. Columns are found by name,
+// so both our files and Pocket files (title,url,time_added,tags,status) are read
+func importCSV(data []byte) (Collection, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return Collection{}, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return Collection{}, errors.New("no 'url' column")
+	}
+
+	field := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+
+	var c Collection
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Collection{}, err
+		}
+
+		b := Bookmark{
+			URL:     field(record, "url"),
+			Folder:  field(record, "folder"),
+			Title:   field(record, "title"),
+			Status:  field(record, "status"),
+			Source:  field(record, "source"),
+			Note:    field(record, "note"),
+			AddedAt: parseTime(field(record, "added_at", "time_added")),
+		}
+		if b.Folder == "" {
+			b.Folder = firstTag(field(record, "tags"), "|")
+		}
+		if b.Status == pocketArchive {
+			b.Status = statusRead
+		}
+		if b.URL != "" {
+			c.Bookmarks = append(c.Bookmarks, b)
+		}
+	}
+
+	return c, nil
+}
+
+// importHTML() reads browser bookmarks and Pocket exports.
+// In browser bookmarks every <H3> is a folder name and the <DL> after it is the folder contents.
+// Pocket files have <H1> sections "Unread" and "Read Archive" and keep tags in an attribute
+func importHTML(data []byte) (Collection, error) {
+	var (
+		c       Collection
+		folders []string // Путь до текущей папки, "" для списков без заголовка
+		heading string   // Название папки, список которой еще не начался
+		status  string
+		current *Bookmark // Ссылка, название которой сейчас читается
+		note    = -1      // Индекс ссылки, описание (<DD>) которой сейчас читается
+	)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return Collection{}, err
+			}
+			return c, nil
+
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			note = -1
+
+			switch token.Data {
+			case "h3":
+				heading = strings.ReplaceAll(readText(tokenizer, "h3"), folderpath.Separator, "-")
+			case "h1":
+				if strings.EqualFold(readText(tokenizer, "h1"), pocketArchiveTitle) {
+					status = statusRead
+				}
+			case "dd":
+				if len(c.Bookmarks) > 0 {
+					note = len(c.Bookmarks) - 1
+				}
+			case "dl":
+				folders = append(folders, heading)
+				if path := folderpath.Join(folders...); heading != "" && path != "" {
+					c.Folders = append(c.Folders, path)
+				}
+				heading = ""
+			case "a":
+				href := attr(token, "href")
+				if href == "" || strings.HasPrefix(strings.ToLower(href), "place:") {
+					// Служебные закладки Firefox ("Most Visited" и т.п.)
+					continue
+				}
+				current = &Bookmark{
+					URL:     href,
+					Folder:  folderpath.Join(folders...),
+					Status:  status,
+					AddedAt: parseTime(attr(token, "add_date", "time_added")),
+				}
+				if current.Folder == "" {
+					current.Folder = firstTag(attr(token, "tags"), ",")
+				}
+			}
+
+		case html.TextToken:
+			if current != nil {
+				current.Title += string(tokenizer.Text())
+			} else if note >= 0 {
+				c.Bookmarks[note].Note = strings.TrimSpace(c.Bookmarks[note].Note + string(tokenizer.Text()))
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+
+			note = -1
+
+			switch string(name) {
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if current != nil {
+					current.Title = strings.TrimSpace(current.Title)
+					if current.Title == current.URL {
+						current.Title = ""
+					}
+					c.Bookmarks = append(c.Bookmarks, *current)
+					current = nil
+				}
+			}
+		}
+	}
+}
+
+// readText() returns the text up to the closing tag
+func readText(tokenizer *html.Tokenizer, tag string) string {
+	var sb strings.Builder
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(sb.String())
+		case html.TextToken:
+			sb.Write(tokenizer.Text())
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == tag {
+				return strings.TrimSpace(sb.String())
+			}
+		}
+	}
+}
+
+// attr() returns the first non-empty attribute from the list
+func attr(token html.Token, names ...string) string {
+	for _, name := range names {
+		for _, a := range token.Attr {
+			if a.Key == name && a.Val != "" {
+				return a.Val
+			}
+		}
+	}
+
+	return ""
+}
+
+func firstTag(tags string, separator string) string {
+	tag, _, _ := strings.Cut(tags, separator)
+
+	return strings.ReplaceAll(strings.TrimSpace(tag), folderpath.Separator, "-")
+}
+
+// parseTime() reads both RFC 3339 and unix time, unknown time is zero
+func parseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil && sec > 0 {
+		return time.Unix(sec, 0)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,192 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const bom = "\xef\xbb\xbf"
+
+func TestImportRoundTrip(t *testing.T) {
+	all := testCollection().Bookmarks
+
+	// Netscape не хранит статус и источник, а ссылки идут по папкам
+	netscape := []Bookmark{all[3], all[1], all[0], all[2]}
+	for i := range netscape {
+		netscape[i].Status, netscape[i].Source = "", ""
+	}
+
+	tests := []struct {
+		format    Format
+		folders   []string
+		bookmarks []Bookmark
+	}{
+		{JSON, testCollection().Folders, all},
+		// В CSV нет пустых папок
+		{CSV, nil, all},
+		{Netscape, []string{"Empty", "Read Later", "Work", "Work/Go", "Work/Go/Talks"}, netscape},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, testCollection(), tt.format); err != nil {
+				t.Fatalf("Export(%s) error = %v", tt.format, err)
+			}
+
+			for _, data := range [][]byte{buf.Bytes(), append([]byte(bom), buf.Bytes()...)} {
+				c, err := Import(data)
+				if err != nil {
+					t.Fatalf("Import(%s) error = %v", tt.format, err)
+				}
+				if len(c.Folders) != 0 || len(tt.folders) != 0 {
+					if !reflect.DeepEqual(c.Folders, tt.folders) {
+						t.Errorf("Import(%s) folders = %q, want %q", tt.format, c.Folders, tt.folders)
+					}
+				}
+				checkBookmarks(t, c.Bookmarks, tt.bookmarks)
+			}
+		})
+	}
+}
+
+const pocketHTML = bom + `<!DOCTYPE html>
+<html>
+<head><title>Pocket Export</title></head>
+<body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/new" time_added="1700000000" tags="go,db">New &amp; shiny</a></li>
+<li><a href="https://example.com/untagged" time_added="1700000001" tags="">https://example.com/untagged</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/old" time_added="1600000000" tags="work/go">Old</a></li>
+</ul>
+</body>
+</html>
+`
+
+const pocketCSV = `title,url,time_added,tags,status
+Go blog,https://go.dev/blog,1700000000,go|blog,unread
+"Quoted, title",https://example.com/old,1600000000,,archive
+`
+
+const firefoxHTML = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks Menu</H1>
+<DL><p>
+    <DT><A HREF="place:sort=8&maxResults=10">Recently Tagged</A>
+    <DT><H3 ADD_DATE="1600000000">Mozilla Firefox</H3>
+    <DL><p>
+        <DT><A HREF="https://support.mozilla.org/" ADD_DATE="1600000001">Get Help</A>
+        <DT><A HREF="place:parent=toolbar_____">Bookmarks Toolbar</A>
+    </DL><p>
+    <DT><H3>News/Tech</H3>
+    <DL><p>
+        <DT><A HREF="https://lwn.net/">LWN</A>
+        <DD>Linux news
+    </DL><p>
+</DL>
+`
+
+func TestImportFixtures(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		folders   []string
+		bookmarks []Bookmark
+	}{
+		{
+			name: "pocket html",
+			data: pocketHTML,
+			bookmarks: []Bookmark{
+				{URL: "https://example.com/new", Folder: "go", Title: "New & shiny", AddedAt: time.Unix(1700000000, 0)},
+				{URL: "https://example.com/untagged", AddedAt: time.Unix(1700000001, 0)},
+				// Ссылки из "Read Archive" считаются прочитанными
+				{URL: "https://example.com/old", Folder: "work-go", Title: "Old", Status: "read", AddedAt: time.Unix(1600000000, 0)},
+			},
+		},
+		{
+			name: "pocket csv",
+			data: pocketCSV,
+			bookmarks: []Bookmark{
+				{URL: "https://go.dev/blog", Folder: "go", Title: "Go blog", Status: "unread", AddedAt: time.Unix(1700000000, 0)},
+				{URL: "https://example.com/old", Title: "Quoted, title", Status: "read", AddedAt: time.Unix(1600000000, 0)},
+			},
+		},
+		{
+			name: "firefox",
+			data: firefoxHTML,
+			// "/" в названии папки не должен создавать вложенную папку
+			folders: []string{"Mozilla Firefox", "News-Tech"},
+			bookmarks: []Bookmark{
+				{URL: "https://support.mozilla.org/", Folder: "Mozilla Firefox", Title: "Get Help", AddedAt: time.Unix(1600000001, 0)},
+				{URL: "https://lwn.net/", Folder: "News-Tech", Title: "LWN", Note: "Linux news"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Import([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if !reflect.DeepEqual(c.Folders, tt.folders) {
+				t.Errorf("Import() folders = %q, want %q", c.Folders, tt.folders)
+			}
+			checkBookmarks(t, c.Bookmarks, tt.bookmarks)
+		})
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"empty", "", ErrNoBookmarks},
+		{"only bom", bom + "\n", ErrNoBookmarks},
+		{"empty json", `{"folders": [], "bookmarks": []}`, ErrNoBookmarks},
+		{"header only", "url,title\n", ErrNoBookmarks},
+		{"only place entries", `<DL><p><DT><A HREF="place:sort=8">Recent</A></DL>`, ErrNoBookmarks},
+	}
+
+	for _, tt := range tests {
+		if _, err := Import([]byte(tt.data)); !errors.Is(err, tt.err) {
+			t.Errorf("Import(%s) error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	for _, data := range []string{"{broken", "title,link\nGo,https://go.dev\n"} {
+		if _, err := Import([]byte(data)); err == nil {
+			t.Errorf("Import(%q) error = nil", data)
+		}
+	}
+}
+
+func checkBookmarks(t *testing.T, got, want []Bookmark) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d bookmarks, want %d: %+v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		// Время сравнивается без учета часового пояса
+		if !got[i].AddedAt.Equal(want[i].AddedAt) {
+			t.Errorf("bookmark %d added at %v, want %v", i, got[i].AddedAt, want[i].AddedAt)
+		}
+		g, w := got[i], want[i]
+		g.AddedAt, w.AddedAt = time.Time{}, time.Time{}
+		if g != w {
+			t.Errorf("bookmark %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package chunker
+
+import (
+	"strings"
+)
+
+// Len() returns the length of the string in UTF-16 code units.
+// Telegram limits the length of messages in these units, not in bytes or runes
+func Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeLen(r)
+	}
+
+	return n
+}
+
+// runeLen() returns the number of UTF-16 code units needed to encode the rune.
+// Runes outside the Basic Multilingual Plane (most emoji) take a surrogate pair
+func runeLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+// Split() groups items into chunks of at most limit UTF-16 code units.
+// Items are never split between chunks unless a single item is longer than the limit
+func Split(items []string, limit int) []string {
+	var (
+		chunks  []string
+		current strings.Builder
+		size    int
+	)
+
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			chunks = append(chunks, current.String())
+		}
+		current.Reset()
+		size = 0
+	}
+
+	for _, item := range items {
+		n := Len(item)
+
+		if n > limit {
+			flush()
+			chunks = append(chunks, cut(item, limit)...)
+			continue
+		}
+
+		if size+n > limit {
+			flush()
+		}
+
+		current.WriteString(item)
+		size += n
+	}
+	flush()
+
+	return chunks
+}
+
+// SplitText() splits the text on paragraph boundaries. Paragraphs longer
+// than the limit are split on line boundaries
+func SplitText(text string, limit int) []string {
+	var items []string
+
+	for _, paragraph := range strings.SplitAfter(text, "\n\n") {
+		if Len(paragraph) > limit {
+			items = append(items, strings.SplitAfter(paragraph, "\n")...)
+			continue
+		}
+		items = append(items, paragraph)
+	}
+
+	return Split(items, limit)
+}
+
+// cut() splits the string into parts of at most limit UTF-16 code units
+// without breaking surrogate pairs
+func cut(s string, limit int) []string {
+	var (
+		parts []string
+		start int
+		size  int
+	)
+
+	for i, r := range s {
+		n := runeLen(r)
+		if size+n > limit {
+			parts = append(parts, s[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+
+	return parts
+}
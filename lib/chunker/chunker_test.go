@@ -0,0 +1,179 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Ограничение Telegram на длину сообщения
+const limit = 4096
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"cyrillic", "привет", 6},
+		{"bmp symbol", "✅", 1},
+		{"emoji", "😀", 2},
+		{"emoji with text", "ok 👍🏻", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Len(tt.s); got != tt.want {
+				t.Errorf("Len(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	cyrillicLine := strings.Repeat("ж", 99) + "\n" // 100 единиц
+	// Эмодзи начинается на 4095-й единице: пара не помещается в первую часть целиком
+	emojiAtBorder := strings.Repeat("a", limit-1) + "😀" + "b"
+	longWord := strings.Repeat("ё", limit*2+10)
+
+	tests := []struct {
+		name   string
+		items  []string
+		chunks int
+	}{
+		{
+			name:   "cyrillic across the limit",
+			items:  repeat(cyrillicLine, 50),
+			chunks: 2,
+		},
+		{
+			name:   "cyrillic exactly at the limit",
+			items:  []string{strings.Repeat("щ", limit)},
+			chunks: 1,
+		},
+		{
+			name:   "emoji at the split point",
+			items:  []string{emojiAtBorder},
+			chunks: 2,
+		},
+		{
+			name:   "emoji-heavy items",
+			items:  repeat(strings.Repeat("🔥", 100)+"\n", 30),
+			chunks: 2,
+		},
+		{
+			name:   "word longer than the limit",
+			items:  []string{longWord},
+			chunks: 3,
+		},
+		{
+			name:   "blank items are dropped",
+			items:  []string{"  ", "\n"},
+			chunks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.items, limit)
+
+			if len(chunks) != tt.chunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.chunks)
+			}
+			checkChunks(t, chunks)
+
+			if tt.chunks > 0 {
+				if got, want := strings.Join(chunks, ""), strings.Join(tt.items, ""); got != want {
+					t.Errorf("chunks don't add up to the input")
+				}
+			}
+		})
+	}
+}
+
+func TestSplitEmojiAtBorder(t *testing.T) {
+	text := strings.Repeat("a", limit-1) + "😀"
+
+	chunks := Split([]string{text}, limit)
+	checkChunks(t, chunks)
+
+	if len(chunks) != 2 || chunks[0] != strings.Repeat("a", limit-1) || chunks[1] != "😀" {
+		t.Errorf("the surrogate pair is not moved to the next chunk whole: %d chunks", len(chunks))
+	}
+}
+
+func TestSplitKeepsItems(t *testing.T) {
+	items := repeat("— элемент списка 📌\n", 400)
+
+	chunks := Split(items, limit)
+	checkChunks(t, chunks)
+
+	for i, chunk := range chunks {
+		if !strings.HasSuffix(chunk, "\n") {
+			t.Errorf("chunk %d ends in the middle of an item", i)
+		}
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	paragraph := strings.Repeat("Абзац текста с эмодзи 🙂. ", 30) + "\n\n"
+	longParagraph := strings.Repeat("строка длинного абзаца 🧩\n", 300) + "\n"
+
+	tests := []struct {
+		name string
+		text string
+		// unit - элемент, который не должен разрываться между частями
+		unit string
+	}{
+		{"paragraphs", strings.Repeat(paragraph, 20), paragraph},
+		{"paragraph longer than the limit is split by lines", longParagraph, "строка длинного абзаца 🧩\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitText(tt.text, limit)
+			if len(chunks) < 2 {
+				t.Fatalf("got %d chunks, want the text to be split", len(chunks))
+			}
+			checkChunks(t, chunks)
+
+			if strings.Join(chunks, "") != tt.text {
+				t.Fatalf("chunks don't add up to the text")
+			}
+			for i, chunk := range chunks {
+				if strings.Trim(strings.ReplaceAll(chunk, tt.unit, ""), "\n") != "" {
+					t.Errorf("chunk %d contains a part of an item", i)
+				}
+			}
+		})
+	}
+}
+
+// checkChunks() checks that every chunk fits the limit and is valid UTF-8,
+// so no surrogate pair or multibyte rune is broken
+func checkChunks(t *testing.T, chunks []string) {
+	t.Helper()
+
+	for i, chunk := range chunks {
+		if n := Len(chunk); n > limit {
+			t.Errorf("chunk %d has length %d, more than %d", i, n, limit)
+		}
+		if !utf8.ValidString(chunk) {
+			t.Errorf("chunk %d is not valid UTF-8", i)
+		}
+		if strings.ContainsRune(chunk, utf8.RuneError) {
+			t.Errorf("chunk %d contains a broken rune", i)
+		}
+	}
+}
+
+func repeat(item string, n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = item
+	}
+
+	return items
+}
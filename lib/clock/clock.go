@@ -0,0 +1,23 @@
+package clock
+
+import "time"
+
+// Clock tells the time and waits. Code that works with time gets the clock from outside,
+// so that it can be run with a fake clock instead of waiting for real time
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
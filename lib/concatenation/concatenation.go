@@ -7,12 +7,23 @@ import (
 
 // Создание пронумерованного списка в строке из списка строк
 func EnumeratedJoin(elements []string) string {
+	return EnumeratedJoinFrom(elements, 1)
+}
+
+// Создание пронумерованного списка, нумерация начинается с first
+func EnumeratedJoinFrom(elements []string, first int) string {
+	return strings.Join(Enumerate(elements, first), "")
+}
+
+// Нумерация элементов списка без объединения в одну строку.
+// Нужна, чтобы длинный список можно было разбить на сообщения по границам элементов
+func Enumerate(elements []string, first int) []string {
 
-	var enumerated strings.Builder
+	enumerated := make([]string, 0, len(elements))
 
 	for i, elem := range elements {
-		enumerated.WriteString(strconv.Itoa(i+1) + ". " + elem + "\n\n")
+		enumerated = append(enumerated, strconv.Itoa(i+first)+". "+elem+"\n\n")
 	}
 
-	return enumerated.String()
+	return enumerated
 }
@@ -0,0 +1,62 @@
+package folderpath
+
+import "strings"
+
+// Separator разделяет вложенные папки в пути: "Work/Go/Talks"
+const Separator = "/"
+
+// Clean() trims spaces around every part of the path and drops empty parts
+func Clean(path string) string {
+	parts := strings.Split(path, Separator)
+	cleaned := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			cleaned = append(cleaned, part)
+		}
+	}
+
+	return strings.Join(cleaned, Separator)
+}
+
+// Join() joins parts of the path, empty parts are skipped
+func Join(parts ...string) string {
+	return Clean(strings.Join(parts, Separator))
+}
+
+// Parent() returns the path of the parent folder ("" for the root folders)
+func Parent(path string) string {
+	i := strings.LastIndex(path, Separator)
+	if i < 0 {
+		return ""
+	}
+
+	return path[:i]
+}
+
+// Base() returns the last part of the path
+func Base(path string) string {
+	return path[strings.LastIndex(path, Separator)+1:]
+}
+
+// Ancestors() returns paths of all folders on the way to the path, including itself.
+// For "Work/Go/Talks" it returns "Work", "Work/Go", "Work/Go/Talks"
+func Ancestors(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	parts := strings.Split(path, Separator)
+	res := make([]string, 0, len(parts))
+
+	for i := range parts {
+		res = append(res, strings.Join(parts[:i+1], Separator))
+	}
+
+	return res
+}
+
+// IsInside() reports whether the path is the folder itself or one of its subfolders
+func IsInside(path, folder string) bool {
+	return path == folder || strings.HasPrefix(path, folder+Separator)
+}
@@ -0,0 +1,154 @@
+package fsm
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
+)
+
+// State is a step of a multi-step flow. The initial state means that no flow is in progress
+type State string
+
+// Input is what the user can send: a command, a text, a button press, a file
+type Input string
+
+// Transition moves the flow from one state to another on the input
+type Transition struct {
+	From  State
+	Input Input
+	To    State
+}
+
+var ErrInvalidTransition = errors.New("invalid transition")
+
+// Просроченные сессии удаляются, когда их накопится столько
+const cleanupSize = 1024
+
+// Machine keeps the state of every key: a user or a user in a chat. All flows use
+// the same transition table. A flow that hasn't moved for the timeout returns to the initial state
+type Machine[K comparable] struct {
+	mu          sync.Mutex
+	initial     State
+	timeout     time.Duration
+	transitions map[State]map[Input]State
+	sessions    map[K]session
+	clock       clock.Clock
+}
+
+type session struct {
+	state   State
+	data    string // Данные текущего шага: выбранная папка, ссылки и т.п.
+	updated time.Time
+}
+
+// New() creates a machine. With zero timeout flows never expire
+func New[K comparable](initial State, timeout time.Duration, transitions ...Transition) *Machine[K] {
+	m := &Machine[K]{
+		initial:     initial,
+		timeout:     timeout,
+		transitions: make(map[State]map[Input]State),
+		sessions:    make(map[K]session),
+		clock:       clock.Real,
+	}
+
+	for _, t := range transitions {
+		if m.transitions[t.From] == nil {
+			m.transitions[t.From] = make(map[Input]State)
+		}
+		m.transitions[t.From][t.Input] = t.To
+	}
+
+	return m
+}
+
+// WithClock() makes the machine measure the timeout by the clock instead of the system time
+func (m *Machine[K]) WithClock(c clock.Clock) *Machine[K] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock = c
+
+	return m
+}
+
+// State() returns the current state of the key and the data saved with it
+func (m *Machine[K]) State(key K) (State, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.session(key)
+
+	return s.state, s.data
+}
+
+// Can() reports whether the input is allowed in the current state of the key
+func (m *Machine[K]) Can(key K, input Input) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.transitions[m.session(key).state][input]
+
+	return ok
+}
+
+// Fire() moves the key to the next state and saves the data for it.
+// If the input isn't allowed in the current state, the state doesn't change
+func (m *Machine[K]) Fire(key K, input Input, data string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.session(key).state
+
+	next, ok := m.transitions[current][input]
+	if !ok {
+		return current, ErrInvalidTransition
+	}
+
+	if next == m.initial {
+		delete(m.sessions, key)
+	} else {
+		m.sessions[key] = session{state: next, data: data, updated: m.clock.Now()}
+		m.cleanup()
+	}
+
+	return next, nil
+}
+
+// Reset() returns the key to the initial state, it's allowed from any state
+func (m *Machine[K]) Reset(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, key)
+}
+
+// session() returns the session of the key. Expired sessions are removed. Must be called with the lock
+func (m *Machine[K]) session(key K) session {
+	s, ok := m.sessions[key]
+	if !ok {
+		return session{state: m.initial}
+	}
+
+	if m.timeout > 0 && m.clock.Now().Sub(s.updated) > m.timeout {
+		delete(m.sessions, key)
+		return session{state: m.initial}
+	}
+
+	return s
+}
+
+// cleanup() removes expired sessions of the keys that never came back. Must be called with the lock
+func (m *Machine[K]) cleanup() {
+	if m.timeout == 0 || len(m.sessions) < cleanupSize {
+		return
+	}
+
+	now := m.clock.Now()
+	for key, s := range m.sessions {
+		if now.Sub(s.updated) > m.timeout {
+			delete(m.sessions, key)
+		}
+	}
+}
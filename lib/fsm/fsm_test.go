@@ -0,0 +1,229 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
+)
+
+const (
+	idle   State = ""
+	naming State = "naming"
+	moving State = "moving"
+
+	start  Input = "start"
+	text   Input = "text"
+	button Input = "button"
+	move   Input = "move"
+)
+
+const timeout = 10 * time.Minute
+
+var transitions = []Transition{
+	{idle, start, naming},
+	{naming, text, naming},
+	{naming, move, moving},
+	{naming, button, idle},
+	{moving, button, idle},
+}
+
+func newMachine() (*Machine[int], *clock.Fake) {
+	c := clock.NewFake(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+
+	return New[int](idle, timeout, transitions...).WithClock(c), c
+}
+
+func TestFire(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []Input
+		want    State
+		wantErr error
+	}{
+		{"start", []Input{start}, naming, nil},
+		{"stay in the state", []Input{start, text, text}, naming, nil},
+		{"next state", []Input{start, move}, moving, nil},
+		{"back to initial", []Input{start, move, button}, idle, nil},
+		{"not allowed in initial", []Input{text}, idle, ErrInvalidTransition},
+		{"not allowed in the middle", []Input{start, move, text}, moving, ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newMachine()
+
+			var (
+				got State
+				err error
+			)
+			for _, input := range tt.inputs {
+				got, err = m.Fire(1, input, string(input))
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fire() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Fire() = %q, want %q", got, tt.want)
+			}
+			if state, _ := m.State(1); state != tt.want {
+				t.Errorf("State() = %q, want %q", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestFireRejectedKeepsData(t *testing.T) {
+	m, _ := newMachine()
+
+	if _, err := m.Fire(1, start, "Work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Fire(1, start, "Other"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Fire() error = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	if state, data := m.State(1); state != naming || data != "Work" {
+		t.Errorf("State() = %q, %q, want %q, %q", state, data, naming, "Work")
+	}
+}
+
+func TestCan(t *testing.T) {
+	m, _ := newMachine()
+
+	if !m.Can(1, start) || m.Can(1, text) {
+		t.Errorf("wrong inputs allowed in the initial state")
+	}
+
+	if _, err := m.Fire(1, start, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Can(1, start) || !m.Can(1, text) || !m.Can(1, move) {
+		t.Errorf("wrong inputs allowed in %q", naming)
+	}
+	if !m.Can(2, start) {
+		t.Errorf("the state of one key affects another")
+	}
+}
+
+func TestReset(t *testing.T) {
+	m, _ := newMachine()
+
+	if _, err := m.Fire(1, start, "data"); err != nil {
+		t.Fatal(err)
+	}
+	m.Reset(1)
+
+	if state, data := m.State(1); state != idle || data != "" {
+		t.Errorf("State() after Reset() = %q, %q", state, data)
+	}
+	if len(m.sessions) != 0 {
+		t.Errorf("Reset() left %d sessions", len(m.sessions))
+	}
+
+	// Сброс без начатой операции ничего не ломает
+	m.Reset(2)
+}
+
+func TestInitialStateDeletesSession(t *testing.T) {
+	m, _ := newMachine()
+
+	for _, input := range []Input{start, move, button} {
+		if _, err := m.Fire(1, input, "data"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(m.sessions) != 0 {
+		t.Errorf("%d sessions left after returning to the initial state", len(m.sessions))
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	m, c := newMachine()
+
+	if _, err := m.Fire(1, start, "data"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Advance(timeout)
+	if state, _ := m.State(1); state != naming {
+		t.Fatalf("the session expired at the timeout, state = %q", state)
+	}
+
+	// Каждый шаг продлевает сессию
+	if _, err := m.Fire(1, text, "data"); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(timeout)
+	if state, _ := m.State(1); state != naming {
+		t.Fatalf("the step didn't extend the session, state = %q", state)
+	}
+
+	c.Advance(time.Second)
+	if state, data := m.State(1); state != idle || data != "" {
+		t.Errorf("State() after the timeout = %q, %q", state, data)
+	}
+	if m.Can(1, text) {
+		t.Errorf("the input of the expired flow is allowed")
+	}
+	if len(m.sessions) != 0 {
+		t.Errorf("the expired session is kept")
+	}
+}
+
+func TestNoTimeout(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	m := New[int](idle, 0, transitions...).WithClock(c)
+
+	if _, err := m.Fire(1, start, ""); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(365 * 24 * time.Hour)
+
+	if state, _ := m.State(1); state != naming {
+		t.Errorf("the session expired with zero timeout")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	m, c := newMachine()
+
+	for key := 0; key < cleanupSize-1; key++ {
+		if _, err := m.Fire(key, start, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Advance(timeout + time.Second)
+
+	// Последняя сессия доводит число сессий до порога и удаляет просроченные
+	if _, err := m.Fire(cleanupSize, start, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.sessions) != 1 {
+		t.Fatalf("%d sessions left after cleanup, want 1", len(m.sessions))
+	}
+	if state, _ := m.State(cleanupSize); state != naming {
+		t.Errorf("cleanup removed the fresh session")
+	}
+}
+
+func TestCleanupBelowThreshold(t *testing.T) {
+	m, c := newMachine()
+
+	for key := 0; key < 10; key++ {
+		if _, err := m.Fire(key, start, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Advance(timeout + time.Second)
+	m.cleanup()
+
+	if len(m.sessions) != 10 {
+		t.Errorf("cleanup ran below the threshold: %d sessions left", len(m.sessions))
+	}
+}
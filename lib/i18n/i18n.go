@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang is a two-letter language code from IETF language tags: "en", "ru"
+type Lang string
+
+const (
+	English Lang = "en"
+	Russian Lang = "ru"
+)
+
+// Default is used for users whose language isn't supported
+const Default = English
+
+// Languages lists supported languages in the order they are offered to users
+var Languages = []Lang{English, Russian}
+
+var names = map[Lang]string{
+	English: "🇬🇧 English",
+	Russian: "🇷🇺 Русский",
+}
+
+// Name() returns the language name written in that language
+func (l Lang) Name() string {
+	if name, ok := names[l]; ok {
+		return name
+	}
+
+	return string(l)
+}
+
+// Parse() returns the supported language for the tag such as "ru" or "en-US"
+func Parse(tag string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+	for _, lang := range Languages {
+		if Lang(base) == lang {
+			return lang, true
+		}
+	}
+
+	return Default, false
+}
+
+// Catalog keeps texts of messages in every language
+type Catalog[K comparable] map[Lang]map[K]string
+
+// Text() returns the message in the language. Messages without translation are taken
+// from the default language. With arguments the message is used as a fmt format
+func (c Catalog[K]) Text(lang Lang, key K, args ...any) string {
+	text, ok := c[lang][key]
+	if !ok {
+		text = c[Default][key]
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+
+	return text
+}
@@ -0,0 +1,123 @@
+package linkcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
+)
+
+// StatusUnreachable is recorded when the site didn't answer: the domain doesn't exist,
+// the connection was refused or timed out
+const StatusUnreachable = 0
+
+const (
+	timeout   = 15 * time.Second
+	userAgent = "Mozilla/5.0 (compatible; LinkChecker/1.0)"
+)
+
+// Checker requests saved links to find the broken ones.
+// No more than concurrency requests are made at the same time
+type Checker struct {
+	client      *http.Client
+	concurrency int
+}
+
+// New() creates a checker. Unless allowPrivateHosts is set, links that resolve
+// to localhost or internal networks are reported as unreachable
+func New(concurrency int, allowPrivateHosts bool) *Checker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateHosts {
+		dialer.Control = urlnorm.DenyPrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Checker{
+		client:      &http.Client{Transport: transport, Timeout: timeout},
+		concurrency: concurrency,
+	}
+}
+
+// Check() returns the status code of every link. The same link is requested once
+func (c *Checker) Check(ctx context.Context, urls []string) map[string]int {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]int, len(urls))
+		seen     = make(map[string]bool, len(urls))
+		sem      = make(chan struct{}, c.concurrency)
+	)
+
+	for _, url := range urls {
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(url string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			status := c.Status(ctx, url)
+
+			mu.Lock()
+			statuses[url] = status
+			mu.Unlock()
+		}(url)
+	}
+
+	wg.Wait()
+
+	return statuses
+}
+
+// Status() requests the link with HEAD. Many sites don't support HEAD or answer it
+// differently, so on a failure the link is requested again with GET
+func (c *Checker) Status(ctx context.Context, url string) int {
+	status := c.request(ctx, http.MethodHead, url)
+	if status != StatusUnreachable && status < http.StatusBadRequest {
+		return status
+	}
+
+	return c.request(ctx, http.MethodGet, url)
+}
+
+func (c *Checker) request(ctx context.Context, method string, url string) int {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return StatusUnreachable
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return StatusUnreachable
+	}
+	// Тело не читается: для проверки достаточно кода ответа
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+// IsDead() tells whether the status means that the link is broken: the site is unreachable,
+// the page is gone or the server fails. Other errors (401, 403, 429) usually mean
+// that the site doesn't let bots in, the page itself may be fine
+func IsDead(status int) bool {
+	return status == StatusUnreachable || status == http.StatusNotFound ||
+		status == http.StatusGone || status >= http.StatusInternalServerError
+}
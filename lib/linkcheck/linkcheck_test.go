@@ -0,0 +1,200 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newServer() starts a server that answers every path with its status: /404 returns 404.
+// Other paths return 200
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Path[1:])
+		if err != nil {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestStatus(t *testing.T) {
+	server := newServer(t)
+	c := New(2, true)
+
+	tests := []struct {
+		path string
+		want int
+		dead bool
+	}{
+		{"/ok", http.StatusOK, false},
+		{"/404", http.StatusNotFound, true},
+		{"/410", http.StatusGone, true},
+		{"/500", http.StatusInternalServerError, true},
+		{"/503", http.StatusServiceUnavailable, true},
+		// Сайт не пускает ботов, страница может быть в порядке
+		{"/403", http.StatusForbidden, false},
+		{"/429", http.StatusTooManyRequests, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := c.Status(context.Background(), server.URL+tt.path)
+			if got != tt.want {
+				t.Errorf("Status() = %d, want %d", got, tt.want)
+			}
+			if IsDead(got) != tt.dead {
+				t.Errorf("IsDead(%d) = %t, want %t", got, IsDead(got), tt.dead)
+			}
+		})
+	}
+}
+
+func TestStatusHeadNotAllowed(t *testing.T) {
+	var methods []string
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	got := New(1, true).Status(context.Background(), server.URL)
+	if got != http.StatusOK {
+		t.Errorf("Status() = %d, want %d", got, http.StatusOK)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Errorf("methods = %v, want HEAD then GET", methods)
+	}
+}
+
+func TestStatusUnreachable(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := New(1, true)
+	c.client.Timeout = 50 * time.Millisecond
+
+	if got := c.Status(context.Background(), server.URL); got != StatusUnreachable {
+		t.Errorf("Status() of a hanging server = %d, want %d", got, StatusUnreachable)
+	}
+	if !IsDead(StatusUnreachable) {
+		t.Errorf("unreachable link isn't dead")
+	}
+
+	// Закрытый порт
+	closed := httptest.NewServer(http.NotFoundHandler())
+	url := closed.URL
+	closed.Close()
+
+	if got := c.Status(context.Background(), url); got != StatusUnreachable {
+		t.Errorf("Status() of a closed port = %d, want %d", got, StatusUnreachable)
+	}
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/a", server.URL + "/a", server.URL + "/b", server.URL + "/a"}
+
+	statuses := New(4, true).Check(context.Background(), urls)
+
+	if len(statuses) != 2 {
+		t.Errorf("got %d statuses, want 2", len(statuses))
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("the server got %d requests, want 2", got)
+	}
+}
+
+func TestCheckConcurrency(t *testing.T) {
+	const (
+		concurrency = 3
+		links       = 20
+	)
+
+	var inFlight, highest atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			top := highest.Load()
+			if n <= top || highest.CompareAndSwap(top, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 0, links)
+	for i := 0; i < links; i++ {
+		urls = append(urls, server.URL+"/"+strconv.Itoa(1000+i))
+	}
+
+	statuses := New(concurrency, true).Check(context.Background(), urls)
+
+	if len(statuses) != links {
+		t.Fatalf("got %d statuses, want %d", len(statuses), links)
+	}
+	if got := highest.Load(); got > concurrency {
+		t.Errorf("%d requests at the same time, the limit is %d", got, concurrency)
+	}
+	if got := highest.Load(); got < 2 {
+		t.Errorf("links were checked one by one, concurrency isn't used")
+	}
+}
+
+func TestPrivateHostRefused(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	got := New(1, false).Status(context.Background(), server.URL)
+
+	if got != StatusUnreachable {
+		t.Errorf("Status() of 127.0.0.1 = %d, want %d", got, StatusUnreachable)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("the private server was requested")
+	}
+}
@@ -0,0 +1,80 @@
+package markup
+
+import "strings"
+
+// Formatter builds texts for one of the Telegram parse modes.
+// All user-controlled strings (folder names, links) must pass through it,
+// otherwise a single "<" or "_" breaks the whole message
+type Formatter interface {
+	ParseMode() string
+	Escape(text string) string
+	Bold(text string) string
+	Italic(text string) string
+	Link(text, url string) string
+}
+
+var (
+	HTML       Formatter = htmlFormatter{}
+	MarkdownV2 Formatter = markdownV2Formatter{}
+)
+
+type htmlFormatter struct{}
+
+var htmlReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+)
+
+func (htmlFormatter) ParseMode() string {
+	return "HTML"
+}
+
+func (htmlFormatter) Escape(text string) string {
+	return htmlReplacer.Replace(text)
+}
+
+func (f htmlFormatter) Bold(text string) string {
+	return "<b>" + f.Escape(text) + "</b>"
+}
+
+func (f htmlFormatter) Italic(text string) string {
+	return "<i>" + f.Escape(text) + "</i>"
+}
+
+func (f htmlFormatter) Link(text, url string) string {
+	return `<a href="` + f.Escape(url) + `">` + f.Escape(text) + "</a>"
+}
+
+type markdownV2Formatter struct{}
+
+// Все эти символы должны экранироваться в MarkdownV2 вне сущностей
+var markdownV2Replacer = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// Внутри (...) ссылки экранируются только ")" и "\"
+var markdownV2URLReplacer = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+
+func (markdownV2Formatter) ParseMode() string {
+	return "MarkdownV2"
+}
+
+func (markdownV2Formatter) Escape(text string) string {
+	return markdownV2Replacer.Replace(text)
+}
+
+func (f markdownV2Formatter) Bold(text string) string {
+	return "*" + f.Escape(text) + "*"
+}
+
+func (f markdownV2Formatter) Italic(text string) string {
+	return "_" + f.Escape(text) + "_"
+}
+
+func (f markdownV2Formatter) Link(text, url string) string {
+	return "[" + f.Escape(text) + "](" + markdownV2URLReplacer.Replace(url) + ")"
+}
@@ -0,0 +1,77 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"plain", HTML.Escape("Go talks"), "Go talks"},
+		{"tags", HTML.Escape("<b>folder</b>"), "&lt;b&gt;folder&lt;/b&gt;"},
+		{"ampersand", HTML.Escape("Q&A &amp;"), "Q&amp;A &amp;amp;"},
+		{"quote", HTML.Escape(`say "hi"`), "say &quot;hi&quot;"},
+		{"markdown untouched", HTML.Escape(`_*[]()~` + "`" + `>#+-=|{}.!\`), `_*[]()~` + "`" + `&gt;#+-=|{}.!\`},
+		{"bold", HTML.Bold("a<b"), "<b>a&lt;b</b>"},
+		{"italic", HTML.Italic("a&b"), "<i>a&amp;b</i>"},
+		{"link", HTML.Link("<Go>", "https://example.com/?a=1&b=2"), `<a href="https://example.com/?a=1&amp;b=2">&lt;Go&gt;</a>`},
+		{"link with quote", HTML.Link("x", `https://example.com/"onclick="`), `<a href="https://example.com/&quot;onclick=&quot;">x</a>`},
+		{"link with brackets", HTML.Link("Go", `https://en.wikipedia.org/wiki/Go_(language)\`), `<a href="https://en.wikipedia.org/wiki/Go_(language)\">Go</a>`},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if HTML.ParseMode() != "HTML" {
+		t.Errorf("ParseMode() = %q, want HTML", HTML.ParseMode())
+	}
+}
+
+func TestMarkdownV2Reserved(t *testing.T) {
+	// Все зарезервированные символы MarkdownV2 и "\"
+	for _, c := range strings.Split("_*[]()~`>#+-=|{}.!\\", "") {
+		if got, want := MarkdownV2.Escape(c), `\`+c; got != want {
+			t.Errorf("Escape(%q) = %q, want %q", c, got, want)
+		}
+		if got, want := MarkdownV2.Escape("a"+c+"b"), `a\`+c+"b"; got != want {
+			t.Errorf("Escape(%q) = %q, want %q", "a"+c+"b", got, want)
+		}
+	}
+}
+
+func TestMarkdownV2(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"plain", MarkdownV2.Escape("Go talks"), "Go talks"},
+		{"html untouched", MarkdownV2.Escape(`<a href="x">&amp;</a>`), `<a href\="x"\>&amp;</a\>`},
+		{"path", MarkdownV2.Escape("Work/Go-talks (2024).md"), `Work/Go\-talks \(2024\)\.md`},
+		{"escaped backslash", MarkdownV2.Escape(`\_`), `\\\_`},
+		{"bold", MarkdownV2.Bold("a*b"), `*a\*b*`},
+		{"italic", MarkdownV2.Italic("snake_case"), `_snake\_case_`},
+		{"link", MarkdownV2.Link("Go [blog]", "https://go.dev/blog"), `[Go \[blog\]](https://go.dev/blog)`},
+		{"link with dots", MarkdownV2.Link("a.b", "https://example.com/a.b?x=1&y=_"), `[a\.b](https://example.com/a.b?x=1&y=_)`},
+		{"link with bracket", MarkdownV2.Link("Go", "https://en.wikipedia.org/wiki/Go_(language)"), `[Go](https://en.wikipedia.org/wiki/Go_(language\))`},
+		{"link with backslash", MarkdownV2.Link("Go", `https://example.com/a\b`), `[Go](https://example.com/a\\b)`},
+		{"link with both", MarkdownV2.Link("(x)", `https://example.com/\)`), `[\(x\)](https://example.com/\\\))`},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if MarkdownV2.ParseMode() != "MarkdownV2" {
+		t.Errorf("ParseMode() = %q, want MarkdownV2", MarkdownV2.ParseMode())
+	}
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake time for the limiter
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *clock) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(rate, burst)
+	l.now = c.now
+
+	return l, c
+}
+
+func TestBurst(t *testing.T) {
+	l, _ := newTestLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow(1) {
+			t.Fatalf("Allow() #%d = false, want true", i+1)
+		}
+	}
+	if l.Allow(1) {
+		t.Errorf("Allow() after the burst = true, want false")
+	}
+
+	// У другого ключа своя корзина
+	if !l.Allow(2) {
+		t.Errorf("Allow() of another key = false, want true")
+	}
+}
+
+func TestRefill(t *testing.T) {
+	l, c := newTestLimiter(2, 2)
+
+	l.Allow(1)
+	l.Allow(1)
+
+	c.advance(250 * time.Millisecond)
+	if l.Allow(1) {
+		t.Errorf("Allow() after half a token = true, want false")
+	}
+
+	c.advance(250 * time.Millisecond)
+	if !l.Allow(1) {
+		t.Errorf("Allow() after a token = false, want true")
+	}
+	if l.Allow(1) {
+		t.Errorf("Allow() after the token is spent = true, want false")
+	}
+
+	// Корзина не наполняется больше burst
+	c.advance(time.Hour)
+	for i := 0; i < 2; i++ {
+		if !l.Allow(1) {
+			t.Fatalf("Allow() #%d after an hour = false, want true", i+1)
+		}
+	}
+	if l.Allow(1) {
+		t.Errorf("Allow() over the burst = true, want false")
+	}
+}
+
+func TestZeroRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		l, _ := newTestLimiter(rate, 1)
+		for i := 0; i < 100; i++ {
+			if !l.Allow(1) {
+				t.Fatalf("rate %v: Allow() #%d = false, want true", rate, i+1)
+			}
+		}
+		if len(l.buckets) != 0 {
+			t.Errorf("rate %v: %d buckets, want 0", rate, len(l.buckets))
+		}
+	}
+}
+
+func TestBurstAtLeastOne(t *testing.T) {
+	l, _ := newTestLimiter(1, 0)
+
+	if !l.Allow(1) {
+		t.Errorf("Allow() with zero burst = false, want true")
+	}
+	if l.Allow(1) {
+		t.Errorf("second Allow() with zero burst = true, want false")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	l, c := newTestLimiter(1, 2)
+
+	for key := 0; key < 1024; key++ {
+		l.Allow(key)
+	}
+	if len(l.buckets) != 1024 {
+		t.Fatalf("%d buckets, want 1024", len(l.buckets))
+	}
+
+	// Корзины еще не наполнились и остаются
+	l.Allow(1024)
+	if len(l.buckets) != 1025 {
+		t.Fatalf("%d buckets before refill, want 1025", len(l.buckets))
+	}
+
+	// Через секунду все корзины, кроме новой, снова полные
+	c.advance(time.Second)
+	l.Allow(2000)
+	if len(l.buckets) != 1 {
+		t.Fatalf("%d buckets after refill, want 1", len(l.buckets))
+	}
+	if _, ok := l.buckets[2000]; !ok {
+		t.Errorf("bucket of the last request is removed")
+	}
+
+	// Удаленная корзина снова выдает полный burst
+	if !l.Allow(1) || !l.Allow(1) || l.Allow(1) {
+		t.Errorf("removed bucket doesn't start full")
+	}
+}
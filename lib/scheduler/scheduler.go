@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
+)
+
+// Task is a periodic job. now is the time of the tick that started the task
+type Task func(ctx context.Context, now time.Time) error
+
+// Scheduler runs tasks periodically alongside the event consumer. It keeps nothing
+// but the time of the next run: tasks read what is due from the storage, so
+// the jobs planned before a restart aren't lost
+type Scheduler struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	tick  time.Duration // Как часто проверяется, не пора ли запустить задачу
+	tasks []*task
+	wg    sync.WaitGroup // Запущенные задачи
+}
+
+type task struct {
+	name    string
+	every   time.Duration
+	next    time.Time
+	running bool // Задача не запускается снова, пока не закончится предыдущий запуск
+	run     Task
+}
+
+// New() creates a scheduler that checks tasks every tick
+func New(c clock.Clock, tick time.Duration) *Scheduler {
+	return &Scheduler{
+		clock: c,
+		tick:  tick,
+	}
+}
+
+// Add() registers the task that runs every interval. The first run is on the first tick
+func (s *Scheduler) Add(name string, every time.Duration, run Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = append(s.tasks, &task{name: name, every: every, run: run})
+}
+
+// Start() runs the tasks until the context is cancelled and waits for the started ones to finish.
+// Errors of tasks are logged, the task runs again after its interval
+func (s *Scheduler) Start(ctx context.Context) {
+	defer s.wg.Wait()
+
+	for {
+		s.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(s.tick):
+		}
+	}
+}
+
+// RunDue() starts the tasks whose time has come, each in its own goroutine, so a slow task
+// doesn't hold up the others. A task that is still running is skipped until it finishes
+func (s *Scheduler) RunDue(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	for _, t := range s.tasks {
+		if t.running || now.Before(t.next) {
+			continue
+		}
+		t.next = now.Add(t.every)
+		t.running = true
+
+		s.wg.Add(1)
+		go s.run(ctx, t, now)
+	}
+}
+
+// Wait() blocks until the started tasks finish
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, t *task, now time.Time) {
+	defer s.wg.Done()
+	defer func() {
+		// Паника задачи не должна останавливать бота вместе с остальными задачами
+		if r := recover(); r != nil {
+			log.Printf("[ERR] scheduler: task '%s' panicked: %v", t.name, r)
+		}
+
+		s.mu.Lock()
+		t.running = false
+		s.mu.Unlock()
+	}()
+
+	if err := t.run(ctx, now); err != nil {
+		log.Printf("[ERR] scheduler: task '%s': %s", t.name, err)
+	}
+}
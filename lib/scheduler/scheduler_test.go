@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
+)
+
+func TestMain(m *testing.M) {
+	// Ошибки задач пишутся в лог, в тестах они ожидаемы
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// counter is a task that remembers when it was run
+type counter struct {
+	runs []time.Time
+	err  error
+}
+
+func (c *counter) run(ctx context.Context, now time.Time) error {
+	c.runs = append(c.runs, now)
+	return c.err
+}
+
+// runDue() runs the due tasks and waits for them, so the test can check their runs
+func runDue(s *Scheduler) {
+	s.RunDue(context.Background())
+	s.Wait()
+}
+
+func TestRunDue(t *testing.T) {
+	start := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+	s := New(c, 10*time.Second)
+
+	task := &counter{}
+	s.Add("task", time.Minute, task.run)
+
+	runDue(s)
+	if len(task.runs) != 1 || !task.runs[0].Equal(start) {
+		t.Fatalf("the task didn't run on the first tick: %v", task.runs)
+	}
+
+	// До истечения интервала задача не запускается
+	for i := 0; i < 5; i++ {
+		c.Advance(10 * time.Second)
+		runDue(s)
+	}
+	if len(task.runs) != 1 {
+		t.Fatalf("the task ran %d times before its interval", len(task.runs))
+	}
+
+	c.Advance(10 * time.Second)
+	runDue(s)
+	if len(task.runs) != 2 || !task.runs[1].Equal(start.Add(time.Minute)) {
+		t.Fatalf("the task didn't run after its interval: %v", task.runs)
+	}
+
+	// Пропущенные тики не запускают задачу несколько раз подряд
+	c.Advance(5 * time.Minute)
+	runDue(s)
+	runDue(s)
+	if len(task.runs) != 3 {
+		t.Errorf("the task ran %d times, want 3", len(task.runs))
+	}
+}
+
+func TestRunDueError(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC))
+	s := New(c, time.Second)
+
+	failing := &counter{err: errors.New("storage is down")}
+	next := &counter{}
+	s.Add("failing", time.Minute, failing.run)
+	s.Add("next", time.Minute, next.run)
+
+	runDue(s)
+	if len(failing.runs) != 1 || len(next.runs) != 1 {
+		t.Fatalf("runs = %d, %d, want both tasks to run", len(failing.runs), len(next.runs))
+	}
+
+	// Задача с ошибкой запускается снова через свой интервал, а не на каждом тике
+	c.Advance(time.Second)
+	runDue(s)
+	if len(failing.runs) != 1 {
+		t.Errorf("the failed task ran again before its interval")
+	}
+
+	c.Advance(time.Minute)
+	runDue(s)
+	if len(failing.runs) != 2 || len(next.runs) != 2 {
+		t.Errorf("runs = %d, %d, want both tasks to run again", len(failing.runs), len(next.runs))
+	}
+}
+
+func TestRunDueSlowTask(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC))
+	s := New(c, 10*time.Second)
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	s.Add("links", time.Minute, func(ctx context.Context, now time.Time) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	reminders := make(chan time.Time, 10)
+	s.Add("reminders", 10*time.Second, func(ctx context.Context, now time.Time) error {
+		reminders <- now
+		return nil
+	})
+
+	s.RunDue(context.Background())
+	<-started
+	<-reminders
+
+	// Медленная задача еще идет, остальные запускаются вовремя
+	for i := 0; i < 10; i++ {
+		c.Advance(10 * time.Second)
+		s.RunDue(context.Background())
+
+		select {
+		case <-reminders:
+		case <-time.After(time.Second):
+			t.Fatalf("the task waited for the slow one on tick %d", i+1)
+		}
+	}
+
+	// Запуск, который еще не закончился, не повторяется
+	select {
+	case <-started:
+		t.Errorf("the slow task started again while running")
+	default:
+	}
+
+	close(release)
+	s.Wait()
+
+	c.Advance(10 * time.Second)
+	runDue(s)
+	if len(started) != 1 {
+		t.Errorf("the slow task didn't run after it finished")
+	}
+}
+
+func TestStart(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC))
+	s := New(c, time.Second)
+
+	runs := make(chan time.Time, 10)
+	s.Add("task", time.Minute, func(ctx context.Context, now time.Time) error {
+		runs <- now
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	first := <-runs
+	if !first.Equal(c.Now()) {
+		t.Errorf("the first run at %s, want %s", first, c.Now())
+	}
+
+	// Часы двигаются, пока планировщик не дождется следующего запуска
+	var second time.Time
+	for waiting := true; waiting; {
+		select {
+		case second = <-runs:
+			waiting = false
+		default:
+			c.Advance(time.Second)
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if got := second.Sub(first); got < time.Minute {
+		t.Errorf("the second run %s after the first, want at least a minute", got)
+	}
+
+	cancel()
+	c.Advance(time.Second)
+	<-done
+}
@@ -0,0 +1,283 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// MaxTextSize is the limit of the saved text in bytes. Longer texts are cut by paragraphs
+	MaxTextSize = 16 << 10
+
+	maxPageSize = 2 << 20 // Больше этого страница не читается
+	timeout     = 20 * time.Second
+	userAgent   = "Mozilla/5.0 (compatible; LinkArchiver/1.0)"
+	cutMark     = "…"
+)
+
+var (
+	ErrNotHTML = errors.New("page is not html or text")
+	ErrNoText  = errors.New("page has no text")
+)
+
+// Элементы, текст которых не относится к содержимому страницы
+var skipped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"iframe": true, "nav": true, "header": true, "footer": true, "aside": true,
+	"form": true, "button": true, "select": true, "head": true,
+}
+
+// Элементы, которые начинают новый абзац
+var blocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "ul": true, "ol": true, "dl": true, "dt": true, "dd": true,
+	"blockquote": true, "pre": true, "table": true, "tr": true, "figcaption": true, "hr": true,
+}
+
+// Page is the readable copy of the page
+type Page struct {
+	Title string
+	Text  string
+}
+
+// Fetcher downloads pages and keeps only their main text
+type Fetcher struct {
+	client *http.Client
+}
+
+// New() creates a fetcher. Unless allowPrivateHosts is set, pages on localhost
+// and internal networks are not downloaded
+func New(allowPrivateHosts bool) *Fetcher {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateHosts {
+		dialer.Control = urlnorm.DenyPrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Fetcher{client: &http.Client{Transport: transport, Timeout: timeout}}
+}
+
+// Fetch() downloads the page and returns its readable copy
+func (f *Fetcher) Fetch(ctx context.Context, url string) (page *Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't fetch snapshot", err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	body := io.LimitReader(resp.Body, maxPageSize)
+
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+		reader, err := charset.NewReader(body, contentType)
+		if err != nil {
+			return nil, err
+		}
+		return Extract(reader)
+	case "text/plain":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return plain(string(data))
+	}
+
+	return nil, ErrNotHTML
+}
+
+// Extract() reads the title and the main text of the HTML page. The text is taken
+// from <article> or <main> if the page has them, scripts, menus and forms are dropped
+func Extract(r io.Reader) (*Page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{}
+	if title := find(doc, "title"); title != nil {
+		page.Title = collapse(text(title))
+	}
+
+	root := find(doc, "article")
+	if root == nil {
+		root = find(doc, "main")
+	}
+	if root == nil {
+		root = find(doc, "body")
+	}
+	if root == nil {
+		return nil, ErrNoText
+	}
+
+	e := &extractor{}
+	e.walk(root)
+	e.flush()
+
+	if len(e.paragraphs) == 0 {
+		return nil, ErrNoText
+	}
+	page.Text = limit(e.paragraphs)
+
+	return page, nil
+}
+
+// plain() returns the copy of the text file
+func plain(data string) (*Page, error) {
+	if !utf8.ValidString(data) {
+		return nil, ErrNotHTML
+	}
+
+	var paragraphs []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	if len(paragraphs) == 0 {
+		return nil, ErrNoText
+	}
+
+	return &Page{Text: limit(paragraphs)}, nil
+}
+
+// extractor collects the text of the page by paragraphs
+type extractor struct {
+	paragraphs []string
+	current    strings.Builder
+}
+
+func (e *extractor) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		e.current.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if skipped[n.Data] {
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && blocks[n.Data]
+	if block {
+		e.flush()
+	}
+	if n.Type == html.ElementNode && n.Data == "li" {
+		e.current.WriteString("• ")
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child)
+	}
+
+	if block {
+		e.flush()
+	}
+}
+
+// flush() finishes the current paragraph. Paragraphs without text are dropped
+func (e *extractor) flush() {
+	paragraph := collapse(e.current.String())
+	e.current.Reset()
+
+	if paragraph != "" && paragraph != "•" {
+		e.paragraphs = append(e.paragraphs, paragraph)
+	}
+}
+
+// limit() joins the paragraphs, the text is cut to MaxTextSize by whole paragraphs
+func limit(paragraphs []string) string {
+	var b strings.Builder
+
+	for _, paragraph := range paragraphs {
+		size := len(paragraph)
+		if b.Len() > 0 {
+			size += 2
+		}
+
+		if b.Len()+size > MaxTextSize {
+			if b.Len() == 0 {
+				// Один абзац длиннее ограничения режется по границе символа
+				cut := MaxTextSize - len(cutMark)
+				for cut > 0 && !utf8.RuneStart(paragraph[cut]) {
+					cut--
+				}
+				return paragraph[:cut] + cutMark
+			}
+			b.WriteString("\n\n" + cutMark)
+			break
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(paragraph)
+	}
+
+	return b.String()
+}
+
+// find() returns the first element with the name, nil if there is none
+func find(n *html.Node, name string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == name {
+		return n
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if res := find(child, name); res != nil {
+			return res
+		}
+	}
+
+	return nil
+}
+
+// text() returns all text inside the node
+func text(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(text(child))
+	}
+
+	return b.String()
+}
+
+// collapse() replaces runs of spaces and line breaks with a single space
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,200 @@
+package urlnorm
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	ErrNotLink     = errors.New("text is not a link")
+	ErrScheme      = errors.New("scheme is not allowed")
+	ErrPrivateHost = errors.New("private host is not allowed")
+	ErrDomain      = errors.New("host is not a registrable domain")
+)
+
+// Схемы, которые можно сохранить. javascript:, file:, data: и прочие отбрасываются
+var allowedSchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+}
+
+// Имена, которые есть только во внутренних сетях
+var privateSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+// Detector decides whether the text is a link that can be saved
+type Detector struct {
+	// Разрешить ссылки на localhost, адреса внутренних сетей и имена без домена верхнего уровня
+	AllowPrivateHosts bool
+}
+
+// Normalize() checks the link and returns it with the https scheme if the scheme is missing:
+// "example.com/article" becomes "https://example.com/article".
+// The host must be an IP address or a domain under a known public suffix
+func (d Detector) Normalize(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\n") {
+		return "", ErrNotLink
+	}
+
+	explicit := strings.Contains(text, "://")
+
+	if !explicit {
+		// "javascript:alert(1)", "mailto:me@example.com", но не "localhost:8080"
+		scheme, rest, ok := strings.Cut(text, ":")
+		if ok && isScheme(scheme) && !strings.Contains(scheme, ".") && !startsWithDigit(rest) {
+			return "", ErrScheme
+		}
+		text = "https://" + text
+	}
+
+	u, err := url.Parse(text)
+	if err != nil {
+		return "", ErrNotLink
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !allowedSchemes[u.Scheme] {
+		return "", ErrScheme
+	}
+
+	// Адрес IPv6 не проходит через idna, его проверяет checkHost()
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) == nil {
+		if host, err = idna.Lookup.ToASCII(host); err != nil {
+			return "", ErrDomain
+		}
+	}
+
+	// Без схемы и порта любое слово выглядело бы как имя во внутренней сети
+	if !explicit && u.Port() == "" && !strings.Contains(host, ".") && net.ParseIP(host) == nil {
+		return "", ErrNotLink
+	}
+
+	if err := d.checkHost(host); err != nil {
+		return "", err
+	}
+
+	// Национальные домены хранятся в punycode, иначе url.String() закодирует их через %
+	switch port := u.Port(); {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"): // IPv6
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	return u.String(), nil
+}
+
+func (d Detector) checkHost(host string) error {
+	if host == "" {
+		return ErrNotLink
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if IsPrivateIP(ip) && !d.AllowPrivateHosts {
+			return ErrPrivateHost
+		}
+		return nil
+	}
+
+	if isPrivateName(host) {
+		if !d.AllowPrivateHosts {
+			return ErrPrivateHost
+		}
+		return nil
+	}
+
+	if !isDomain(host) {
+		return ErrDomain
+	}
+
+	return nil
+}
+
+// isDomain() reports whether the host is registered under a known public suffix.
+// "example.com" and "user.github.io" are domains, "co.uk" and "notes.txt" are not
+// The host must be in punycode, as the public suffix table stores national domains this way
+func isDomain(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+
+	suffix, icann := publicsuffix.PublicSuffix(host)
+	// Для неизвестных зон PublicSuffix возвращает последнюю метку без флага icann
+	if !icann && !strings.Contains(suffix, ".") {
+		return false
+	}
+
+	_, err := publicsuffix.EffectiveTLDPlusOne(host)
+
+	return err == nil
+}
+
+func isPrivateName(host string) bool {
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return true
+	}
+
+	for _, suffix := range privateSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPrivateIP() reports whether the address belongs to the host itself or to an internal network
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// DenyPrivateAddress() is the Control function of net.Dialer that refuses connections
+// to localhost and internal networks. The address is checked after the name is resolved,
+// so a public domain can't be pointed to an internal host
+func DenyPrivateAddress(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && IsPrivateIP(ip) {
+		return ErrPrivateHost
+	}
+
+	return nil
+}
+
+// isScheme() checks the syntax of the URL scheme: a letter followed by letters, digits, "+", "-" or "."
+func isScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && '0' <= s[0] && s[0] <= '9'
+}
@@ -0,0 +1,159 @@
+package urlnorm
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		// Без схемы ссылка открывается по https
+		{"example.com", "https://example.com"},
+		{"example.com/article?id=1", "https://example.com/article?id=1"},
+		{"EXAMPLE.com/Article", "https://example.com/Article"},
+		{"www.bbc.co.uk/news", "https://www.bbc.co.uk/news"},
+		{"user.github.io", "https://user.github.io"},
+		{"example.com:8080/a", "https://example.com:8080/a"},
+		{"  example.com  ", "https://example.com"},
+
+		// Указанная схема не меняется
+		{"http://example.com", "http://example.com"},
+		{"https://example.com/a", "https://example.com/a"},
+		{"HTTPS://Example.com/", "https://example.com/"},
+
+		{"пример.рф", "https://xn--e1afmkfd.xn--p1ai"},
+		{"https://пример.рф/статья", "https://xn--e1afmkfd.xn--p1ai/%D1%81%D1%82%D0%B0%D1%82%D1%8C%D1%8F"},
+
+		{"8.8.8.8", "https://8.8.8.8"},
+		{"http://[2001:4860:4860::8888]/", "http://[2001:4860:4860::8888]/"},
+		{"http://[2001:4860:4860::8888]:8080/", "http://[2001:4860:4860::8888]:8080/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Detector{}.Normalize(tt.text)
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  error
+	}{
+		{"", ErrNotLink},
+		{"word", ErrNotLink},
+		{"two words.com", ErrNotLink},
+		{"localhost", ErrNotLink},
+
+		{"example.notatld", ErrDomain},
+		{"co.uk", ErrDomain},
+		{"notes.txt", ErrDomain},
+		{"-bad-.com", ErrDomain},
+		{"https://exa_mple.com", ErrDomain},
+
+		{"javascript:alert(1)", ErrScheme},
+		{"JavaScript:alert(document.cookie)", ErrScheme},
+		{"file:///etc/passwd", ErrScheme},
+		{"file://host/share", ErrScheme},
+		{"data:text/html,hi", ErrScheme},
+		{"mailto:me@example.com", ErrScheme},
+		{"ftp://example.com", ErrScheme},
+
+		{"localhost:8080", ErrPrivateHost},
+		{"http://localhost", ErrPrivateHost},
+		{"http://printer.local/", ErrPrivateHost},
+		{"http://app.internal/", ErrPrivateHost},
+		{"127.0.0.1", ErrPrivateHost},
+		{"http://127.0.0.1:8080", ErrPrivateHost},
+		{"10.0.0.1", ErrPrivateHost},
+		{"192.168.1.1/admin", ErrPrivateHost},
+		{"http://169.254.169.254/latest/meta-data", ErrPrivateHost},
+		{"http://[::1]/", ErrPrivateHost},
+		{"http://[fe80::1]:8080/", ErrPrivateHost},
+	}
+
+	d := Detector{}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got, err := d.Normalize(tt.text); !errors.Is(err, tt.err) {
+				t.Errorf("Normalize(%q) = %q, %v, want %v", tt.text, got, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeAllowPrivateHosts(t *testing.T) {
+	d := Detector{AllowPrivateHosts: true}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"localhost:8080", "https://localhost:8080"},
+		{"http://localhost:3000/x", "http://localhost:3000/x"},
+		{"printer.local", "https://printer.local"},
+		{"127.0.0.1", "https://127.0.0.1"},
+		{"192.168.1.1/admin", "https://192.168.1.1/admin"},
+		{"http://[::1]/", "http://[::1]/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := d.Normalize(tt.text)
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	// Проверки схемы и домена от этого не зависят
+	for _, text := range []string{"localhost", "javascript:alert(1)", "file:///etc/passwd", "example.notatld"} {
+		if _, err := d.Normalize(text); err == nil {
+			t.Errorf("Normalize(%q) error = nil", text)
+		}
+	}
+}
+
+func TestDenyPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"127.0.0.1:80", true},
+		{"10.1.2.3:443", true},
+		{"172.16.0.1:443", true},
+		{"192.168.0.10:8080", true},
+		{"169.254.169.254:80", true},
+		{"0.0.0.0:80", true},
+		{"[::1]:443", true},
+		{"[fd00::1]:443", true},
+		{"93.184.216.34:443", false},
+		{"[2001:4860:4860::8888]:443", false},
+	}
+
+	for _, tt := range tests {
+		err := DenyPrivateAddress("tcp", tt.address, nil)
+		if denied := errors.Is(err, ErrPrivateHost); denied != tt.denied {
+			t.Errorf("DenyPrivateAddress(%q) = %v, want denied %t", tt.address, err, tt.denied)
+		}
+	}
+
+	if IsPrivateIP(net.ParseIP("1.1.1.1")) {
+		t.Errorf("IsPrivateIP(1.1.1.1) = true")
+	}
+}
@@ -0,0 +1,94 @@
+package urlnorm
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var ErrNoHost = errors.New("url has no host")
+
+// Параметры, которые добавляют рекламные и аналитические системы.
+// На содержимое страницы они не влияют
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"yclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"ref_src": true,
+	"si":      true,
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Canonical() returns the canonical form of the URL, so that the same page
+// saved from different places is stored once:
+//   - the scheme and the host are lowercased, international hosts are written in punycode;
+//   - the default port of the scheme is dropped;
+//   - tracking parameters (utm_*, fbclid, ...) are removed, the rest are sorted;
+//   - the trailing slash of the path is removed.
+func Canonical(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", ErrNoHost
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if host, err := idna.Lookup.ToASCII(u.Hostname()); err == nil && host != u.Hostname() {
+		u.Host = strings.Replace(u.Host, u.Hostname(), host, 1)
+	}
+
+	if port := u.Port(); port != "" && defaultPorts[u.Scheme] == port {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+
+	// Запрос с ошибками кодирования оставляется как есть, чтобы не потерять параметры
+	if query, err := url.ParseQuery(u.RawQuery); err == nil {
+		u.RawQuery = cleanQuery(query)
+	}
+	u.ForceQuery = false
+
+	// Закодированный "/" в конце пути (%2F) - часть имени, а не лишний слэш
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+		if path, err := url.PathUnescape(u.RawPath); err == nil {
+			u.Path = path
+		}
+	} else {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+
+	return u.String(), nil
+}
+
+// cleanQuery() removes tracking parameters and encodes the rest sorted by key
+func cleanQuery(query url.Values) string {
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+
+	return query.Encode()
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
@@ -0,0 +1,84 @@
+package urlnorm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"already canonical", "https://example.com/article", "https://example.com/article"},
+		{"host lowercased", "https://Example.COM/Article", "https://example.com/Article"},
+		{"scheme lowercased", "HTTPS://example.com/a", "https://example.com/a"},
+		{"spaces trimmed", "  https://example.com/a \n", "https://example.com/a"},
+
+		{"utm removed", "https://example.com/a?utm_source=tg&utm_medium=post&UTM_Campaign=x", "https://example.com/a"},
+		{"fbclid removed", "https://example.com/a?fbclid=IwAR0abc", "https://example.com/a"},
+		{"other trackers removed", "https://example.com/a?gclid=1&yclid=2&si=3", "https://example.com/a"},
+		{"parameters kept", "https://example.com/search?q=go&utm_source=tg&page=2", "https://example.com/search?page=2&q=go"},
+
+		{"http port 80", "http://example.com:80/a", "http://example.com/a"},
+		{"https port 443", "https://example.com:443/a", "https://example.com/a"},
+		{"other port kept", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"80 kept for https", "https://example.com:80/a", "https://example.com:80/a"},
+
+		{"trailing slash", "https://example.com/a/", "https://example.com/a"},
+		{"several slashes", "https://example.com/a///", "https://example.com/a"},
+		{"root slash", "https://example.com/", "https://example.com"},
+		{"slash before query", "https://example.com/a/?id=1", "https://example.com/a?id=1"},
+		{"escaped slash kept", "https://example.com/a%2F/", "https://example.com/a%2F"},
+		{"empty query", "https://example.com/a?", "https://example.com/a"},
+
+		{"query sorted", "https://example.com/a?b=2&a=1&c=3", "https://example.com/a?a=1&b=2&c=3"},
+		{"repeated key order kept", "https://example.com/a?tag=go&tag=db", "https://example.com/a?tag=go&tag=db"},
+		{"fragment kept", "https://example.com/a#Section-2", "https://example.com/a#Section-2"},
+		{"fragment after query", "https://example.com/a/?b=2&a=1#top", "https://example.com/a?a=1&b=2#top"},
+
+		{"idn to punycode", "https://Пример.РФ/статья", "https://xn--e1afmkfd.xn--p1ai/%D1%81%D1%82%D0%B0%D1%82%D1%8C%D1%8F"},
+		{"punycode unchanged", "https://xn--e1afmkfd.xn--p1ai/", "https://xn--e1afmkfd.xn--p1ai"},
+		{"idn with port", "http://münchen.de:80/", "http://xn--mnchen-3ya.de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonical(tt.url)
+			if err != nil {
+				t.Fatalf("Canonical(%q) error = %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonical(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+
+			// Каноническая ссылка не меняется при повторном приведении
+			again, err := Canonical(got)
+			if err != nil || again != got {
+				t.Errorf("Canonical(%q) = %q, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalErrors(t *testing.T) {
+	tests := []struct {
+		url string
+		err error
+	}{
+		{"example.com/a", ErrNoHost},
+		{"/relative/path", ErrNoHost},
+		{"", ErrNoHost},
+	}
+
+	for _, tt := range tests {
+		if _, err := Canonical(tt.url); !errors.Is(err, tt.err) {
+			t.Errorf("Canonical(%q) error = %v, want %v", tt.url, err, tt.err)
+		}
+	}
+
+	if _, err := Canonical("https://exa mple.com/"); err == nil {
+		t.Errorf("Canonical() of a host with a space error = nil")
+	}
+}
@@ -0,0 +1,360 @@
+package when
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Час, на который назначается напоминание, если указан только день
+const DefaultHour = 9
+
+var (
+	ErrUnknownTime = errors.New("unknown time")
+	ErrPastTime    = errors.New("time has passed")
+)
+
+// Слова, которые не влияют на время: "tomorrow at 9", "завтра в 9"
+var fillers = map[string]bool{
+	"at": true, "on": true, "в": true, "во": true,
+}
+
+var (
+	relativeWords = map[string]bool{"in": true, "через": true}
+	// "a week", "in an hour"
+	oneWords = map[string]bool{"a": true, "an": true}
+)
+
+// Дни относительно сегодняшнего
+var days = map[string]int{
+	"today": 0, "сегодня": 0,
+	"tomorrow": 1, "завтра": 1,
+	"послезавтра": 2,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday, "воскресенье": time.Sunday, "вс": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday, "понедельник": time.Monday, "пн": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "вторник": time.Tuesday, "вт": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday, "среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "четверг": time.Thursday, "чт": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday, "пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday, "суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+}
+
+// unit is a step of the relative time: "in 3 days". Days and months are counted
+// by the calendar, so "in 1 day" keeps the time of day when the clocks are changed
+type unit struct {
+	duration time.Duration
+	days     int
+	months   int
+}
+
+var units = map[string]unit{}
+
+func init() {
+	add := func(u unit, names ...string) {
+		for _, name := range names {
+			units[name] = u
+		}
+	}
+
+	add(unit{duration: time.Minute}, "minute", "minutes", "min", "mins", "минуту", "минуты", "минут", "мин")
+	add(unit{duration: time.Hour}, "hour", "hours", "h", "час", "часа", "часов", "ч")
+	add(unit{days: 1}, "day", "days", "d", "день", "дня", "дней", "сутки")
+	add(unit{days: 7}, "week", "weeks", "неделю", "недели", "недель")
+	add(unit{months: 1}, "month", "months", "месяц", "месяца", "месяцев")
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	datePattern    = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{4}))?$`)
+	isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+)
+
+// moment collects the parts of the time mentioned in the text
+type moment struct {
+	date     time.Time // Полночь выбранного дня, нулевое время - день не указан
+	weekday  bool      // День указан днем недели, его можно перенести на неделю
+	year     bool      // Год указан явно
+	clock    bool
+	hour     int
+	minute   int
+	relative time.Time // Время "через N ..."
+}
+
+// Parse() reads the time of a reminder in the location of now. It understands English and Russian:
+//
+//	"in 3 days", "in an hour", "через 2 часа"
+//	"tomorrow 9:00", "today at 18:30", "завтра в 9"
+//	"friday 10:00", "в пятницу"
+//	"25.12 18:00", "2024-12-25"
+//	"18:00" - today or tomorrow if the time has passed
+//
+// A day without the time means DefaultHour. The result is always after now
+func Parse(text string, now time.Time) (time.Time, error) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return time.Time{}, ErrUnknownTime
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var m moment
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if relativeWords[token] {
+			n, u, used, ok := relative(tokens[i+1:])
+			if !ok || !m.relative.IsZero() {
+				return time.Time{}, ErrUnknownTime
+			}
+			m.relative = now.Add(time.Duration(n)*u.duration).AddDate(0, n*u.months, n*u.days)
+			i += used
+			continue
+		}
+
+		if offset, ok := days[token]; ok && m.date.IsZero() {
+			m.date = today.AddDate(0, 0, offset)
+			continue
+		}
+
+		if weekday, ok := weekdays[token]; ok && m.date.IsZero() {
+			m.date = today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+			m.weekday = true
+			continue
+		}
+
+		if date, year, ok := parseDate(token, today); ok && m.date.IsZero() {
+			m.date, m.year = date, year
+			continue
+		}
+
+		if hour, minute, ok := parseClock(token); ok && !m.clock {
+			m.clock, m.hour, m.minute = true, hour, minute
+			continue
+		}
+
+		return time.Time{}, ErrUnknownTime
+	}
+
+	return m.resolve(now, today)
+}
+
+// resolve() builds the time from the collected parts
+func (m moment) resolve(now time.Time, today time.Time) (time.Time, error) {
+	if !m.relative.IsZero() {
+		if !m.date.IsZero() {
+			return time.Time{}, ErrUnknownTime
+		}
+		if !m.clock {
+			return m.relative, nil
+		}
+		// "через 2 дня в 10:00"
+		m.date = time.Date(m.relative.Year(), m.relative.Month(), m.relative.Day(), 0, 0, 0, 0, now.Location())
+	}
+
+	if m.date.IsZero() && !m.clock {
+		return time.Time{}, ErrUnknownTime
+	}
+
+	hour, minute := DefaultHour, 0
+	if m.clock {
+		hour, minute = m.hour, m.minute
+	}
+
+	date := m.date
+	if date.IsZero() {
+		date = today
+	}
+
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, now.Location())
+
+	if !t.After(now) {
+		switch {
+		case m.date.IsZero():
+			// Только время, которое сегодня уже прошло
+			t = t.AddDate(0, 0, 1)
+		case m.weekday:
+			t = t.AddDate(0, 0, 7)
+		case m.relative.IsZero() && !m.year && date.Before(today):
+			// Дата без года, которая в этом году уже прошла
+			t = t.AddDate(1, 0, 0)
+		}
+	}
+
+	if !t.After(now) {
+		return time.Time{}, ErrPastTime
+	}
+
+	return t, nil
+}
+
+func tokenize(text string) []string {
+	text = strings.ToLower(strings.ReplaceAll(text, ",", " "))
+
+	var tokens []string
+
+	for _, token := range strings.Fields(text) {
+		if !fillers[token] {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+// relative() reads "3 days" or "a week" after "in". It returns how many tokens are used
+func relative(tokens []string) (n int, u unit, used int, ok bool) {
+	if len(tokens) == 0 {
+		return 0, unit{}, 0, false
+	}
+
+	// "через час", "через неделю" - без числа
+	if u, ok := units[tokens[0]]; ok {
+		return 1, u, 1, true
+	}
+	if len(tokens) < 2 {
+		return 0, unit{}, 0, false
+	}
+
+	if oneWords[tokens[0]] {
+		n = 1
+	} else {
+		var err error
+		if n, err = strconv.Atoi(tokens[0]); err != nil || n <= 0 {
+			return 0, unit{}, 0, false
+		}
+	}
+
+	u, ok = units[tokens[1]]
+
+	return n, u, 2, ok
+}
+
+// parseClock() reads "9", "9:30", "21:30", "9am", "9:30pm"
+func parseClock(token string) (hour int, minute int, ok bool) {
+	match := clockPattern.FindStringSubmatch(token)
+	if match == nil {
+		return 0, 0, false
+	}
+
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+
+	return hour, minute, true
+}
+
+// parseDate() reads "25.12", "25.12.2024" and "2024-12-25". year tells whether the year is given
+func parseDate(token string, today time.Time) (date time.Time, year bool, ok bool) {
+	var y, m, d int
+
+	if match := datePattern.FindStringSubmatch(token); match != nil {
+		d, _ = strconv.Atoi(match[1])
+		m, _ = strconv.Atoi(match[2])
+		y, year = today.Year(), match[3] != ""
+		if year {
+			y, _ = strconv.Atoi(match[3])
+		}
+	} else if match := isoDatePattern.FindStringSubmatch(token); match != nil {
+		y, _ = strconv.Atoi(match[1])
+		m, _ = strconv.Atoi(match[2])
+		d, _ = strconv.Atoi(match[3])
+		year = true
+	} else {
+		return time.Time{}, false, false
+	}
+
+	date = time.Date(y, time.Month(m), d, 0, 0, 0, 0, today.Location())
+	if date.Day() != d || int(date.Month()) != m {
+		// 31.02 и подобные несуществующие даты
+		return time.Time{}, false, false
+	}
+
+	return date, year, true
+}
+
+// Location() returns the time zone by its name ("Europe/Moscow") or its UTC offset
+// ("+3", "UTC+03:00", "GMT-5:30"). The name of the returned location can be passed back
+func Location(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	if offset, ok := parseOffset(name); ok {
+		if offset == 0 {
+			return time.UTC, nil
+		}
+		return time.FixedZone(offsetName(offset), offset), nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+var offsetPattern = regexp.MustCompile(`^(?i:utc|gmt)?\s*([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// parseOffset() returns the UTC offset in seconds
+func parseOffset(s string) (int, bool) {
+	if strings.EqualFold(s, "utc") || strings.EqualFold(s, "gmt") {
+		return 0, true
+	}
+
+	match := offsetPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, false
+	}
+
+	hours, _ := strconv.Atoi(match[2])
+	minutes := 0
+	if match[3] != "" {
+		minutes, _ = strconv.Atoi(match[3])
+	}
+	if hours > 14 || minutes > 59 {
+		return 0, false
+	}
+
+	offset := hours*3600 + minutes*60
+	if match[1] == "-" {
+		offset = -offset
+	}
+
+	return offset, true
+}
+
+// offsetName() returns the name of the fixed zone: "UTC+03:00"
+func offsetName(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+
+	return "UTC" + sign + twoDigits(offset/3600) + ":" + twoDigits(offset%3600/60)
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,184 @@
+package when
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("can't load time zone %s: %s", name, err)
+	}
+
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+	// Пятница, 15 марта 2024, 14:30 по Москве
+	now := time.Date(2024, time.March, 15, 14, 30, 0, 0, moscow)
+
+	date := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, moscow)
+	}
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"tomorrow 9:00", date(time.March, 16, 9, 0)},
+		{"завтра в 9", date(time.March, 16, 9, 0)},
+		{"tomorrow", date(time.March, 16, DefaultHour, 0)},
+		{"in 3 days", date(time.March, 18, 14, 30)},
+		{"через 2 часа", date(time.March, 15, 16, 30)},
+		{"in an hour", date(time.March, 15, 15, 30)},
+		{"in a week", date(time.March, 22, 14, 30)},
+		{"in 3 days at 10:00", date(time.March, 18, 10, 0)},
+		{"18:00", date(time.March, 15, 18, 0)},
+		// Время, которое сегодня уже прошло, - завтра
+		{"9:00", date(time.March, 16, 9, 0)},
+		{"14:30", date(time.March, 16, 14, 30)},
+		{"9pm", date(time.March, 15, 21, 0)},
+		{"friday 10:00", date(time.March, 22, 10, 0)},
+		{"в пятницу", date(time.March, 22, 9, 0)},
+		{"monday", date(time.March, 18, 9, 0)},
+		{"25.12 18:00", date(time.December, 25, 18, 0)},
+		{"01.03", time.Date(2025, time.March, 1, 9, 0, 0, 0, moscow)},
+		{"2024-12-25", date(time.December, 25, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+			if got.Location() != moscow {
+				t.Errorf("Parse(%q) is in %s, want %s", tt.text, got.Location(), moscow)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 14, 30, 0, 0, time.FixedZone("UTC+05:00", 5*60*60))
+
+	tests := []struct {
+		text string
+		err  error
+	}{
+		{"", ErrUnknownTime},
+		{"someday", ErrUnknownTime},
+		{"in many days", ErrUnknownTime},
+		{"in 0 days", ErrUnknownTime},
+		{"25:00", ErrUnknownTime},
+		{"31.02", ErrUnknownTime},
+		{"13pm", ErrUnknownTime},
+		{"tomorrow in 2 hours", ErrUnknownTime},
+		{"01.01.2020", ErrPastTime},
+		{"today 10:00", ErrPastTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if _, err := Parse(tt.text, now); !errors.Is(err, tt.err) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.text, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseDST(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	// 31 марта 2024 часы в Берлине переводятся с 02:00 на 03:00
+	now := time.Date(2024, time.March, 30, 10, 0, 0, 0, berlin)
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"tomorrow 9:00", time.Date(2024, time.March, 31, 9, 0, 0, 0, berlin)},
+		// Время суток сохраняется, хотя в сутках 23 часа
+		{"in 1 day", time.Date(2024, time.March, 31, 10, 0, 0, 0, berlin)},
+		{"in a week", time.Date(2024, time.April, 6, 10, 0, 0, 0, berlin)},
+		// Часы отсчитываются точно
+		{"in 24 hours", time.Date(2024, time.March, 31, 11, 0, 0, 0, berlin)},
+		// 02:30 в этот день не бывает, время сдвигается вперед
+		{"tomorrow 2:30", time.Date(2024, time.March, 31, 3, 30, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	_, offset := time.Date(2024, time.March, 31, 9, 0, 0, 0, berlin).Zone()
+	if offset != 2*60*60 {
+		t.Errorf("summer time offset = %d, the test expects the clocks changed", offset)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantName   string
+		wantOffset int
+	}{
+		{"", "UTC", 0},
+		{"UTC", "UTC", 0},
+		{"gmt", "UTC", 0},
+		{"+3", "UTC+03:00", 3 * 60 * 60},
+		{"UTC+03:00", "UTC+03:00", 3 * 60 * 60},
+		{"GMT-5:30", "UTC-05:30", -(5*60*60 + 30*60)},
+		{"+0530", "UTC+05:30", 5*60*60 + 30*60},
+		{"utc-0", "UTC", 0},
+		{"Europe/Moscow", "Europe/Moscow", 3 * 60 * 60},
+		{"Asia/Kolkata", "Asia/Kolkata", 5*60*60 + 30*60},
+	}
+
+	at := time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Location(tt.name)
+			if err != nil {
+				t.Fatalf("Location(%q) error = %v", tt.name, err)
+			}
+			if loc.String() != tt.wantName {
+				t.Errorf("Location(%q) = %s, want %s", tt.name, loc, tt.wantName)
+			}
+			if _, offset := at.In(loc).Zone(); offset != tt.wantOffset {
+				t.Errorf("Location(%q) offset = %d, want %d", tt.name, offset, tt.wantOffset)
+			}
+
+			// Имя зоны можно сохранить и прочитать снова
+			again, err := Location(loc.String())
+			if err != nil || again.String() != loc.String() {
+				t.Errorf("Location(%q) can't read its own name: %v", loc, err)
+			}
+		})
+	}
+}
+
+func TestLocationErrors(t *testing.T) {
+	for _, name := range []string{"Mars/Olympus", "+15", "UTC+3:75", "moscow time"} {
+		if _, err := Location(name); err == nil {
+			t.Errorf("Location(%q) error = nil, want an error", name)
+		}
+	}
+}
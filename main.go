@@ -4,11 +4,18 @@ import (
 	"context"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Часовые пояса напоминаний не зависят от системной базы зон
 
 	tgClient "github.com/hahaclassic/golang-telegram-bot.git/clients/telegram"
 	event_consumer "github.com/hahaclassic/golang-telegram-bot.git/consumer/event-consumer"
 	"github.com/hahaclassic/golang-telegram-bot.git/events/telegram"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/clock"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/scheduler"
 	"github.com/hahaclassic/golang-telegram-bot.git/storage/sqlite"
 )
 
@@ -16,6 +23,15 @@ const (
 	tgBotHost         = "api.telegram.org"
 	sqliteStoragePath = "data/sqlite/data.db"
 	batchSize         = 100
+	pageSize          = 10
+	rateLimit         = 1 // Запросов в секунду от одного пользователя
+	rateBurst         = 10
+	schedulerTick     = 10 * time.Second
+	remindersInterval = time.Minute // Как часто проверяются напоминания, точность отправки
+	digestsInterval   = 5 * time.Minute
+	checkInterval     = 10 * time.Minute
+	snapshotsInterval = time.Minute
+	checkConcurrency  = 8 // Одновременных запросов при проверке ссылок
 )
 
 func main() {
@@ -38,8 +54,43 @@ func main() {
 		log.Fatalf("can't init storage: %s", err)
 	}
 
+	token := mustToken()
+
 	// Create events Processor
-	eventsProcessor := telegram.New(tgClient.New(tgBotHost, mustToken()), s)
+	eventsProcessor := telegram.New(tgClient.New(tgBotHost, token), s, telegram.Config{
+		PageSize:          pageSize,
+		AllowPrivateHosts: *allowPrivateHosts,
+		RateLimit:         rateLimit,
+		RateBurst:         rateBurst,
+		AllowedUsers:      mustAllowedUsers(),
+		Clock:             clock.Real,
+		CheckConcurrency:  checkConcurrency,
+	})
+
+	if *metricsAddr != "" {
+		// Метрики expvar доступны по адресу /debug/vars
+		go func() {
+			log.Printf("metrics server is stopped: %s", http.ListenAndServe(*metricsAddr, nil))
+		}()
+	}
+
+	// Без имени бота нельзя отличить команды, адресованные ему в группах
+	if err := eventsProcessor.Identify(); err != nil {
+		log.Fatalf("can't start bot: %s", err)
+	}
+
+	// Меню команд не обязательно для работы бота, поэтому ошибка только логируется
+	if err := eventsProcessor.RegisterCommands(); err != nil {
+		log.Printf("can't register bot commands: %s", err)
+	}
+
+	// Периодические задачи работают рядом с обработкой сообщений
+	sched := scheduler.New(clock.Real, schedulerTick)
+	sched.Add("reminders", remindersInterval, eventsProcessor.SendReminders)
+	sched.Add("digests", digestsInterval, eventsProcessor.SendDigests)
+	sched.Add("links", checkInterval, eventsProcessor.CheckLinks)
+	sched.Add("snapshots", snapshotsInterval, eventsProcessor.SaveSnapshots)
+	go sched.Start(context.Background())
 
 	log.Print("[START]")
 
@@ -50,6 +101,42 @@ func main() {
 	}
 }
 
+var allowPrivateHosts = flag.Bool(
+	"allow-private-hosts",
+	false,
+	"allow saving links to localhost and private networks",
+)
+
+var allowedUsers = flag.String(
+	"allowed-users",
+	"",
+	"comma-separated telegram user ids that can use the bot, everyone if empty",
+)
+
+var metricsAddr = flag.String(
+	"metrics-addr",
+	"",
+	"address of the http server with expvar metrics, e.g. localhost:8080",
+)
+
+func mustAllowedUsers() []int {
+	var ids []int
+
+	for _, field := range strings.Split(*allowedUsers, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			log.Fatalf("invalid user id '%s' in allowed users", field)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
 func mustToken() string {
 	token := flag.String(
 		"tg-bot-token",
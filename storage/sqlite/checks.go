@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Условие битой ссылки: несколько проверок подряд не удались
+var deadCondition = `checkFailures >= ` + strconv.Itoa(storage.DeadAfterFailures)
+
+// GetPagesToCheck() returns the links of all users that haven't been checked since before,
+// never checked links first
+func (s *Storage) GetPagesToCheck(ctx context.Context, before time.Time, limit int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get pages to check", err) }()
+
+	q := `SELECT rowid, url, userID, folder FROM pages WHERE checked < ? ORDER BY checked, rowid LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, q, before.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		page := &storage.Page{}
+		if err := rows.Scan(&page.ID, &page.URL, &page.UserID, &page.Folder); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// SetCheckResult() records the status code of the link and the time of the check.
+// Failed checks are counted, a successful check resets the counter
+func (s *Storage) SetCheckResult(ctx context.Context, id int, status int, failed bool, checked time.Time) error {
+	q := `UPDATE pages SET checkStatus = ?, checked = ?,
+		checkFailures = CASE WHEN ? THEN checkFailures + 1 ELSE 0 END WHERE rowid = ?`
+
+	if _, err := s.db.ExecContext(ctx, q, status, checked.Unix(), failed, id); err != nil {
+		return errhandling.Wrap("can't set check result", err)
+	}
+
+	return nil
+}
+
+// GetDeadPages() returns one page of the user's links found broken by the last check
+func (s *Storage) GetDeadPages(ctx context.Context, userID int, limit, offset int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get dead pages", err) }()
+
+	q := `SELECT rowid, url, folder, status, source, note, checkStatus, checkFailures, checked, ` + hasSnapshot + ` FROM pages
+		WHERE userID = ? AND ` + deadCondition + ` ORDER BY folder, rowid LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var checked int64
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Folder, &page.Status, &page.Source, &page.Note, &page.HTTPStatus, &page.CheckFailures, &checked, &page.Snapshot); err != nil {
+			return nil, err
+		}
+		page.Checked = time.Unix(checked, 0)
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// CountDeadPages() returns the number of the user's broken links
+func (s *Storage) CountDeadPages(ctx context.Context, userID int) (int, error) {
+	q := `SELECT COUNT(*) FROM pages WHERE userID = ? AND ` + deadCondition
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, userID).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count dead pages", err)
+	}
+
+	return count, nil
+}
+
+// RemoveDeadPages() deletes all broken links of the user and returns how many were deleted
+func (s *Storage) RemoveDeadPages(ctx context.Context, userID int) (int, error) {
+	q := `DELETE FROM pages WHERE userID = ? AND ` + deadCondition
+
+	res, err := s.db.ExecContext(ctx, q, userID)
+	if err != nil {
+		return 0, errhandling.Wrap("can't remove dead pages", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errhandling.Wrap("can't remove dead pages", err)
+	}
+
+	return int(n), nil
+}
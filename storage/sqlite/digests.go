@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// GetDigest() returns the digest settings of the user with the chosen folders.
+// Users who haven't set up the digest get a turned off one
+func (s *Storage) GetDigest(ctx context.Context, userID int) (d *storage.Digest, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get digest", err) }()
+
+	d = &storage.Digest{UserID: userID}
+
+	q := `SELECT period, weekday, hour, sent FROM digests WHERE userID = ?`
+
+	var sent int64
+
+	err = s.db.QueryRowContext(ctx, q, userID).Scan(&d.Period, &d.Weekday, &d.Hour, &sent)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == nil {
+		d.Sent = time.Unix(sent, 0)
+	}
+
+	q = `SELECT folder FROM digestFolders WHERE userID = ? ORDER BY folder`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var folder string
+
+	for rows.Next() {
+		if err := rows.Scan(&folder); err != nil {
+			return nil, err
+		}
+		d.Folders = append(d.Folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// SetDigest() saves the schedule of the digest. The chosen folders are saved by SetDigestFolders()
+func (s *Storage) SetDigest(ctx context.Context, d *storage.Digest) error {
+	q := `INSERT INTO digests (userID, period, weekday, hour, sent) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (userID) DO UPDATE SET period = excluded.period, weekday = excluded.weekday,
+		hour = excluded.hour, sent = excluded.sent`
+
+	if _, err := s.db.ExecContext(ctx, q, d.UserID, d.Period, d.Weekday, d.Hour, d.Sent.Unix()); err != nil {
+		return errhandling.Wrap("can't set digest", err)
+	}
+
+	return nil
+}
+
+// SetDigestFolders() replaces the folders from which the digest takes links
+func (s *Storage) SetDigestFolders(ctx context.Context, userID int, folders []string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't set digest folders", err) }()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM digestFolders WHERE userID = ?`, userID); err != nil {
+		return err
+	}
+
+	q := `INSERT OR IGNORE INTO digestFolders (userID, folder) VALUES (?, ?)`
+
+	for _, folder := range folders {
+		if _, err := s.db.ExecContext(ctx, q, userID, folder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetDigestSent() remembers when the digest was sent last time
+func (s *Storage) SetDigestSent(ctx context.Context, userID int, sent time.Time) error {
+	q := `UPDATE digests SET sent = ? WHERE userID = ?`
+
+	if _, err := s.db.ExecContext(ctx, q, sent.Unix(), userID); err != nil {
+		return errhandling.Wrap("can't set digest sent time", err)
+	}
+
+	return nil
+}
+
+// GetDigests() returns the schedules of all turned on digests, without folders
+func (s *Storage) GetDigests(ctx context.Context) (digests []storage.Digest, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get digests", err) }()
+
+	q := `SELECT userID, period, weekday, hour, sent FROM digests WHERE period != ?`
+
+	rows, err := s.db.QueryContext(ctx, q, storage.DigestOff)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sent int64
+
+	for rows.Next() {
+		var d storage.Digest
+		if err := rows.Scan(&d.UserID, &d.Period, &d.Weekday, &d.Hour, &sent); err != nil {
+			return nil, err
+		}
+		d.Sent = time.Unix(sent, 0)
+		digests = append(digests, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+// GetDigestPages() returns links for the digest from the chosen folders: unread links first,
+// then the read ones, in random order. Archived links aren't included
+func (s *Storage) GetDigestPages(ctx context.Context, userID int, limit int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get digest pages", err) }()
+
+	q := `SELECT p.rowid, p.url, p.folder, p.status, p.source, p.note FROM pages p
+		WHERE p.userID = ? AND p.status != ? AND (
+			NOT EXISTS (SELECT 1 FROM digestFolders d WHERE d.userID = p.userID)
+			OR EXISTS (SELECT 1 FROM digestFolders d WHERE d.userID = p.userID
+				AND (p.folder = d.folder OR substr(p.folder, 1, length(d.folder) + 1) = d.folder || ?)))
+		ORDER BY p.status, RANDOM() LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, storage.StatusArchived, folderpath.Separator, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Folder, &page.Status, &page.Source, &page.Note); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
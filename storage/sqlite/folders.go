@@ -2,37 +2,71 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/folderpath"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
 )
 
-// NewFolder creates a new folder for user in the storage
+// NewFolder creates a new folder for user in the storage.
+// Missing parent folders ("Work" and "Work/Go" for "Work/Go/Talks") are created too
 func (s *Storage) NewFolder(ctx context.Context, userID int, folder string) error {
 
-	q := `INSERT INTO folders (userID, folder) VALUES (?, ?)`
+	q := `INSERT INTO folders (userID, folder, parent) SELECT ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM folders WHERE userID = ? AND folder = ?)`
 
-	if _, err := s.db.ExecContext(ctx, q, userID, folder); err != nil {
-		return errhandling.Wrap("can't save page", err)
+	for _, path := range folderpath.Ancestors(folder) {
+		if _, err := s.db.ExecContext(ctx, q, userID, path, folderpath.Parent(path), userID, path); err != nil {
+			return errhandling.Wrap("can't save folder", err)
+		}
 	}
 
 	return nil
 }
 
-// RemoveFolder() deletes the required folder
+// RemoveFolder() deletes the required folder with all its subfolders
 func (s *Storage) RemoveFolder(ctx context.Context, userID int, folder string) error {
-	q := `DELETE FROM pages WHERE userID = ? AND folder = ?`
+	prefix := folder + folderpath.Separator
 
-	if _, err := s.db.ExecContext(ctx, q, userID, folder); err != nil {
-		return errhandling.Wrap("can't remove folder from table 'pages'", err)
-	}
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		// Напоминания об удаленных ссылках больше не нужны
+		q := `DELETE FROM reminders WHERE pageID IN (SELECT rowid FROM pages
+			WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?))`
 
-	q = `DELETE FROM folders WHERE userID = ? AND folder = ?`
+		if _, err := tx.ExecContext(ctx, q, userID, folder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't remove folder from table 'reminders'", err)
+		}
 
-	if _, err := s.db.ExecContext(ctx, q, userID, folder); err != nil {
-		return errhandling.Wrap("can't remove folder from table 'folders'", err)
-	}
+		q = `DELETE FROM pages WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
 
-	return nil
+		if _, err := tx.ExecContext(ctx, q, userID, folder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't remove folder from table 'pages'", err)
+		}
+
+		q = `DELETE FROM folders WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, userID, folder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't remove folder from table 'folders'", err)
+		}
+
+		// Участники удаленной папки теряют к ней доступ, приглашения перестают работать
+		for _, table := range []string{"members", "invites"} {
+			q = `DELETE FROM ` + table + ` WHERE ownerID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+			if _, err := tx.ExecContext(ctx, q, userID, folder, prefix, prefix); err != nil {
+				return errhandling.Wrap("can't remove folder from table '"+table+"'", err)
+			}
+		}
+
+		q = `DELETE FROM digestFolders WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, userID, folder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't remove folder from table 'digestFolders'", err)
+		}
+
+		return nil
+	})
 }
 
 // GetFolder() returns list of URL links in folder
@@ -67,7 +101,7 @@ func (s *Storage) GetFolder(ctx context.Context, userID int, folder string) (url
 func (s *Storage) GetListOfFolders(ctx context.Context, userID int) (names []string, err error) {
 	defer func() { err = errhandling.WrapIfErr("can't select all folders", err) }()
 
-	q := `SELECT folder FROM folders WHERE userID = ?` // Get all folders
+	q := `SELECT folder FROM folders WHERE userID = ? ORDER BY folder` // Get all folders
 
 	rows, err := s.db.QueryContext(ctx, q, userID)
 	if err != nil {
@@ -91,6 +125,94 @@ func (s *Storage) GetListOfFolders(ctx context.Context, userID int) (names []str
 	return names, nil
 }
 
+// GetFoldersPage() returns one page of the list of folders
+func (s *Storage) GetFoldersPage(ctx context.Context, userID int, limit, offset int) (names []string, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't select page of folders", err) }()
+
+	q := `SELECT folder FROM folders WHERE userID = ? ORDER BY folder LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var temp string
+
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&temp); err != nil {
+			return nil, err
+		}
+		names = append(names, temp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// CountFolders() returns the number of folders of the user
+func (s *Storage) CountFolders(ctx context.Context, userID int) (int, error) {
+	q := `SELECT COUNT(*) FROM folders WHERE userID = ?`
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, userID).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count folders", err)
+	}
+
+	return count, nil
+}
+
+// CountSubfolders() returns the number of direct subfolders
+func (s *Storage) CountSubfolders(ctx context.Context, userID int, parent string) (int, error) {
+	q := `SELECT COUNT(*) FROM folders WHERE userID = ? AND parent = ?`
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, userID, parent).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count subfolders", err)
+	}
+
+	return count, nil
+}
+
+// GetFolderID() returns the id of the folder. The id is short enough to be passed in callback data
+func (s *Storage) GetFolderID(ctx context.Context, userID int, folder string) (int, error) {
+	q := `SELECT rowid FROM folders WHERE userID = ? AND folder = ?`
+
+	var id int
+
+	err := s.db.QueryRowContext(ctx, q, userID, folder).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, storage.ErrFolderNotFound
+	}
+	if err != nil {
+		return 0, errhandling.Wrap("can't get folder id", err)
+	}
+
+	return id, nil
+}
+
+// GetFolderByID() returns the path of the folder by its id
+func (s *Storage) GetFolderByID(ctx context.Context, userID int, id int) (string, error) {
+	q := `SELECT folder FROM folders WHERE userID = ? AND rowid = ?`
+
+	var folder string
+
+	err := s.db.QueryRowContext(ctx, q, userID, id).Scan(&folder)
+	if err == sql.ErrNoRows {
+		return "", storage.ErrFolderNotFound
+	}
+	if err != nil {
+		return "", errhandling.Wrap("can't get folder", err)
+	}
+
+	return folder, nil
+}
+
 // IsFolderExists() checks if folder exists in the storage
 func (s *Storage) IsFolderExist(ctx context.Context, userID int, folder string) (bool, error) {
 	q := `SELECT COUNT(*) FROM folders WHERE userID = ? AND folder = ?`
@@ -104,19 +226,117 @@ func (s *Storage) IsFolderExist(ctx context.Context, userID int, folder string)
 	return count > 0, nil
 }
 
-// RenameFolder() changes the folder name to a new one
+// RenameFolder() changes the folder path to a new one. Subfolders and pages are moved with it,
+// so the same method is used both for renaming and for moving folders
 func (s *Storage) RenameFolder(ctx context.Context, userID int, newFolder, oldFolder string) error {
-	q := `UPDATE folders SET folder = ? WHERE userID = ? AND folder = ?`
+	prefix := oldFolder + folderpath.Separator
+
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		q := `UPDATE folders SET parent = ? || substr(parent, length(?) + 1)
+			WHERE userID = ? AND (parent = ? OR substr(parent, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, newFolder, oldFolder, userID, oldFolder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't rename folder", err)
+		}
+
+		q = `UPDATE folders SET folder = ? || substr(folder, length(?) + 1)
+			WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, newFolder, oldFolder, userID, oldFolder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't rename folder", err)
+		}
+
+		q = `UPDATE folders SET parent = ? WHERE userID = ? AND folder = ?`
+
+		if _, err := tx.ExecContext(ctx, q, folderpath.Parent(newFolder), userID, newFolder); err != nil {
+			return errhandling.Wrap("can't rename folder", err)
+		}
+
+		q = `UPDATE pages SET folder = ? || substr(folder, length(?) + 1)
+			WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, newFolder, oldFolder, userID, oldFolder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't rename folder", err)
+		}
+
+		// Общий доступ к папке сохраняется после переименования
+		for _, table := range []string{"members", "invites"} {
+			q = `UPDATE ` + table + ` SET folder = ? || substr(folder, length(?) + 1)
+				WHERE ownerID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+			if _, err := tx.ExecContext(ctx, q, newFolder, oldFolder, userID, oldFolder, prefix, prefix); err != nil {
+				return errhandling.Wrap("can't rename folder", err)
+			}
+		}
+
+		q = `UPDATE digestFolders SET folder = ? || substr(folder, length(?) + 1)
+			WHERE userID = ? AND (folder = ? OR substr(folder, 1, length(?)) = ?)`
+
+		if _, err := tx.ExecContext(ctx, q, newFolder, oldFolder, userID, oldFolder, prefix, prefix); err != nil {
+			return errhandling.Wrap("can't rename folder", err)
+		}
+
+		return nil
+	})
+}
+
+// GetSubfolders() returns one page of the direct subfolders. Empty parent means the root folders
+func (s *Storage) GetSubfolders(ctx context.Context, userID int, parent string, limit, offset int) (folders []storage.Folder, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't select subfolders", err) }()
+
+	q := `SELECT f.folder, EXISTS (SELECT 1 FROM folders c WHERE c.userID = f.userID AND c.parent = f.folder)
+		FROM folders f WHERE f.userID = ? AND f.parent = ? ORDER BY f.folder LIMIT ? OFFSET ?`
 
-	if _, err := s.db.ExecContext(ctx, q, newFolder, userID, oldFolder); err != nil {
-		return errhandling.Wrap("can't rename folder", err)
+	rows, err := s.db.QueryContext(ctx, q, userID, parent, limit, offset)
+	if err != nil {
+		return nil, err
 	}
 
-	q = `UPDATE pages SET folder = ? WHERE userID = ? AND folder = ?`
+	defer rows.Close()
 
-	if _, err := s.db.ExecContext(ctx, q, newFolder, userID, oldFolder); err != nil {
-		return errhandling.Wrap("can't rename folder", err)
+	for rows.Next() {
+		var folder storage.Folder
+		if err := rows.Scan(&folder.Path, &folder.HasSubfolders); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return folders, nil
+}
+
+// CountUnread() returns the number of unread pages in every folder of the user
+func (s *Storage) CountUnread(ctx context.Context, userID int) (counters map[string]int, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't count unread pages", err) }()
+
+	q := `SELECT folder, COUNT(*) FROM pages WHERE userID = ? AND status = ? GROUP BY folder`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, storage.StatusUnread)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counters = make(map[string]int)
+
+	var (
+		folder string
+		count  int
+	)
+
+	for rows.Next() {
+		if err := rows.Scan(&folder, &count); err != nil {
+			return nil, err
+		}
+		counters[folder] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
 }
@@ -3,6 +3,8 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
 	"github.com/hahaclassic/golang-telegram-bot.git/storage"
@@ -18,22 +20,33 @@ func (s *Storage) NewPage(url string, userID int, folder string) *storage.Page {
 
 // Save() adds page in the storage
 func (s *Storage) Save(ctx context.Context, p *storage.Page) error {
-	q := `INSERT INTO pages (url, userID, folder) VALUES (?, ?, ?)`
+	q := `INSERT INTO pages (url, userID, folder, status, source, created, note) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	if _, err := s.db.ExecContext(ctx, q, p.URL, p.UserID, p.Folder); err != nil {
+	if p.Created.IsZero() {
+		p.Created = time.Now()
+	}
+
+	res, err := s.db.ExecContext(ctx, q, p.URL, p.UserID, p.Folder, p.Status, p.Source, p.Created.Unix(), p.Note)
+	if err != nil {
 		return errhandling.Wrap("can't save page", err)
 	}
 
+	id, err := res.LastInsertId()
+	if err != nil {
+		return errhandling.Wrap("can't get page id", err)
+	}
+	p.ID = int(id)
+
 	return nil
 }
 
 // PickRandom() picks random page in the storage
 func (s *Storage) PickRandom(ctx context.Context, userID int) (*storage.Page, error) {
-	q := `SELECT url FROM pages WHERE userID = ? ORDER BY RANDOM() LIMIT 1`
+	q := `SELECT rowid, url, folder, status, note, ` + hasSnapshot + ` FROM pages WHERE userID = ? ORDER BY RANDOM() LIMIT 1`
 
-	var url string
+	page := &storage.Page{UserID: userID}
 
-	err := s.db.QueryRowContext(ctx, q, userID).Scan(&url)
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&page.ID, &page.URL, &page.Folder, &page.Status, &page.Note, &page.Snapshot)
 
 	if err == sql.ErrNoRows {
 		return nil, storage.ErrNoSavedPages
@@ -42,10 +55,7 @@ func (s *Storage) PickRandom(ctx context.Context, userID int) (*storage.Page, er
 		return nil, errhandling.Wrap("can't pick random page:", err)
 	}
 
-	return &storage.Page{
-		URL:    url,
-		UserID: userID,
-	}, nil
+	return page, nil
 }
 
 // Remove() deletes the required page
@@ -71,3 +81,248 @@ func (s *Storage) IsExist(ctx context.Context, page *storage.Page) (bool, error)
 
 	return count > 0, nil
 }
+
+// GetPage() returns the user's page by its id
+func (s *Storage) GetPage(ctx context.Context, userID int, id int) (*storage.Page, error) {
+	q := `SELECT url, folder, status, source, note, ` + hasSnapshot + ` FROM pages WHERE rowid = ? AND userID = ?`
+
+	page := &storage.Page{ID: id, UserID: userID}
+
+	err := s.db.QueryRowContext(ctx, q, id, userID).Scan(&page.URL, &page.Folder, &page.Status, &page.Source, &page.Note, &page.Snapshot)
+
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrPageNotFound
+	}
+	if err != nil {
+		return nil, errhandling.Wrap("can't get page", err)
+	}
+
+	return page, nil
+}
+
+// SetNote() changes the note of the page, an empty note removes it
+func (s *Storage) SetNote(ctx context.Context, userID int, id int, note string) error {
+	q := `UPDATE pages SET note = ? WHERE rowid = ? AND userID = ?`
+
+	res, err := s.db.ExecContext(ctx, q, note, id, userID)
+	if err != nil {
+		return errhandling.Wrap("can't set page note", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errhandling.Wrap("can't set page note", err)
+	}
+	if n == 0 {
+		return storage.ErrPageNotFound
+	}
+
+	return nil
+}
+
+// SetStatus() changes the read status of the page
+func (s *Storage) SetStatus(ctx context.Context, userID int, id int, status storage.Status) error {
+	q := `UPDATE pages SET status = ? WHERE rowid = ? AND userID = ?`
+
+	res, err := s.db.ExecContext(ctx, q, status, id, userID)
+	if err != nil {
+		return errhandling.Wrap("can't set page status", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errhandling.Wrap("can't set page status", err)
+	}
+	if n == 0 {
+		return storage.ErrPageNotFound
+	}
+
+	return nil
+}
+
+// GetUnread() returns one page of the unread links of the user, oldest first
+func (s *Storage) GetUnread(ctx context.Context, userID int, limit, offset int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get unread pages", err) }()
+
+	q := `SELECT rowid, url, folder, note FROM pages WHERE userID = ? AND status = ? ORDER BY rowid LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, storage.StatusUnread, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID, Status: storage.StatusUnread}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Folder, &page.Note); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// CountUnreadPages() returns the number of unread links of the user in all folders
+func (s *Storage) CountUnreadPages(ctx context.Context, userID int) (int, error) {
+	q := `SELECT COUNT(*) FROM pages WHERE userID = ? AND status = ?`
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, userID, storage.StatusUnread).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count unread pages", err)
+	}
+
+	return count, nil
+}
+
+// GetPages() returns one page of the links in the folder, in the order they were saved
+func (s *Storage) GetPages(ctx context.Context, userID int, folder string, limit, offset int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get pages", err) }()
+
+	q := `SELECT rowid, url, status, source, note, checkStatus, checkFailures, checked FROM pages
+		WHERE userID = ? AND folder = ? ORDER BY rowid LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, folder, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var checked int64
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID, Folder: folder}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Status, &page.Source, &page.Note, &page.HTTPStatus, &page.CheckFailures, &checked); err != nil {
+			return nil, err
+		}
+		if checked != 0 {
+			page.Checked = time.Unix(checked, 0)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// CountPages() returns the number of links in the folder
+func (s *Storage) CountPages(ctx context.Context, userID int, folder string) (int, error) {
+	q := `SELECT COUNT(*) FROM pages WHERE userID = ? AND folder = ?`
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, userID, folder).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count pages", err)
+	}
+
+	return count, nil
+}
+
+// GetAllPages() returns all pages of the user grouped by folders
+func (s *Storage) GetAllPages(ctx context.Context, userID int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get all pages", err) }()
+
+	q := `SELECT rowid, url, folder, status, source, created, note FROM pages WHERE userID = ? ORDER BY folder, rowid`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var created int64
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Folder, &page.Status, &page.Source, &created, &page.Note); err != nil {
+			return nil, err
+		}
+		if created != 0 {
+			page.Created = time.Unix(created, 0)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Search() returns the user's pages whose URL or folder contains every word of the query,
+// the most recent first. Without words the most recent pages are returned
+func (s *Storage) Search(ctx context.Context, userID int, query string, limit, offset int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't search pages", err) }()
+
+	q := `SELECT rowid, url, folder, status, source, note FROM pages WHERE userID = ?`
+	args := []any{userID}
+
+	for _, word := range strings.Fields(query) {
+		pattern := "%" + likeEscaper.Replace(word) + "%"
+		q += ` AND (url LIKE ? ESCAPE '\' OR folder LIKE ? ESCAPE '\' OR note LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	q += ` ORDER BY rowid DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		page := &storage.Page{UserID: userID}
+		if err := rows.Scan(&page.ID, &page.URL, &page.Folder, &page.Status, &page.Source, &page.Note); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Символы, которые в LIKE имеют особое значение
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// FindFolders() returns folders of the user where the page is saved
+func (s *Storage) FindFolders(ctx context.Context, userID int, url string) (folders []string, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't find folders of page", err) }()
+
+	q := `SELECT DISTINCT folder FROM pages WHERE userID = ? AND url = ? ORDER BY folder`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var temp string
+
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&temp); err != nil {
+			return nil, err
+		}
+		folders = append(folders, temp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
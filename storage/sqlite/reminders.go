@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// AddReminder() saves the reminder. The time is stored in unix seconds
+func (s *Storage) AddReminder(ctx context.Context, r *storage.Reminder) error {
+	q := `INSERT INTO reminders (userID, pageID, at) VALUES (?, ?, ?)`
+
+	res, err := s.db.ExecContext(ctx, q, r.UserID, r.PageID, r.At.Unix())
+	if err != nil {
+		return errhandling.Wrap("can't add reminder", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return errhandling.Wrap("can't get reminder id", err)
+	}
+	r.ID = int(id)
+
+	return nil
+}
+
+// GetDueReminders() returns the reminders whose time has come, the earliest first
+func (s *Storage) GetDueReminders(ctx context.Context, now time.Time) (reminders []storage.Reminder, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get due reminders", err) }()
+
+	q := `SELECT rowid, userID, pageID, at FROM reminders WHERE at <= ? ORDER BY at`
+
+	rows, err := s.db.QueryContext(ctx, q, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var at int64
+
+	for rows.Next() {
+		var r storage.Reminder
+		if err := rows.Scan(&r.ID, &r.UserID, &r.PageID, &at); err != nil {
+			return nil, err
+		}
+		r.At = time.Unix(at, 0)
+		reminders = append(reminders, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+// RemoveReminder() deletes the reminder that has been sent
+func (s *Storage) RemoveReminder(ctx context.Context, id int) error {
+	q := `DELETE FROM reminders WHERE rowid = ?`
+
+	if _, err := s.db.ExecContext(ctx, q, id); err != nil {
+		return errhandling.Wrap("can't remove reminder", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// CreateInvite() saves the invite code to the folder. Only the owner of the folder
+// and members with the owner role can invite
+func (s *Storage) CreateInvite(ctx context.Context, userID int, folder storage.SharedFolder, role storage.Role, code string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't create invite", err) }()
+
+	if folder.OwnerID == userID {
+		ok, err := s.IsFolderExist(ctx, userID, folder.Folder)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return storage.ErrFolderNotFound
+		}
+	} else {
+		member, err := s.role(ctx, userID, folder.OwnerID, folder.Folder)
+		if err != nil {
+			return err
+		}
+		if member < storage.RoleOwner {
+			return storage.ErrAccessDenied
+		}
+	}
+
+	q := `INSERT INTO invites (code, ownerID, folder, role) VALUES (?, ?, ?, ?)`
+
+	_, err = s.db.ExecContext(ctx, q, code, folder.OwnerID, folder.Folder, role)
+
+	return err
+}
+
+// JoinFolder() makes the user a member of the folder from the invite. The role of a member
+// who joins again is never lowered. For the owner of the folder nothing changes
+func (s *Storage) JoinFolder(ctx context.Context, userID int, code string) (shared *storage.SharedFolder, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't join folder", err) }()
+
+	shared = &storage.SharedFolder{}
+
+	q := `SELECT ownerID, folder, role FROM invites WHERE code = ?`
+
+	err = s.db.QueryRowContext(ctx, q, code).Scan(&shared.OwnerID, &shared.Folder, &shared.Role)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if shared.OwnerID == userID {
+		shared.Role = storage.RoleOwner
+		return shared, nil
+	}
+
+	q = `INSERT INTO members (ownerID, folder, userID, role) VALUES (?, ?, ?, ?)
+		ON CONFLICT (ownerID, folder, userID) DO UPDATE SET role = max(role, excluded.role)`
+
+	if _, err := s.db.ExecContext(ctx, q, shared.OwnerID, shared.Folder, userID, shared.Role); err != nil {
+		return nil, err
+	}
+
+	q = `SELECT rowid, role FROM members WHERE ownerID = ? AND folder = ? AND userID = ?`
+
+	if err := s.db.QueryRowContext(ctx, q, shared.OwnerID, shared.Folder, userID).Scan(&shared.ID, &shared.Role); err != nil {
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+// GetSharedFolders() returns the folders of other users where the user has at least the given role
+func (s *Storage) GetSharedFolders(ctx context.Context, userID int, minRole storage.Role) (folders []storage.SharedFolder, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get shared folders", err) }()
+
+	q := `SELECT rowid, ownerID, folder, role FROM members WHERE userID = ? AND role >= ? ORDER BY folder`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, minRole)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var folder storage.SharedFolder
+		if err := rows.Scan(&folder.ID, &folder.OwnerID, &folder.Folder, &folder.Role); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// GetSharedFolder() returns the shared folder by the membership id if the user is the member
+func (s *Storage) GetSharedFolder(ctx context.Context, userID int, id int) (*storage.SharedFolder, error) {
+	q := `SELECT ownerID, folder, role FROM members WHERE rowid = ? AND userID = ?`
+
+	folder := &storage.SharedFolder{ID: id}
+
+	err := s.db.QueryRowContext(ctx, q, id, userID).Scan(&folder.OwnerID, &folder.Folder, &folder.Role)
+	if err == sql.ErrNoRows {
+		return nil, errhandling.Wrap("can't get shared folder", storage.ErrAccessDenied)
+	}
+	if err != nil {
+		return nil, errhandling.Wrap("can't get shared folder", err)
+	}
+
+	return folder, nil
+}
+
+// GetSharedPages() returns one page of the links of the shared folder.
+// Links are selected through the membership, so other users get nothing
+func (s *Storage) GetSharedPages(ctx context.Context, userID int, id int, limit, offset int) (pages []*storage.Page, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get shared pages", err) }()
+
+	q := `SELECT p.rowid, p.url, p.userID, p.folder, p.status, p.source, p.note, p.checkStatus, p.checkFailures, p.checked
+		FROM pages p JOIN members m ON p.userID = m.ownerID AND p.folder = m.folder
+		WHERE m.rowid = ? AND m.userID = ? ORDER BY p.rowid LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, q, id, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var checked int64
+
+	for rows.Next() {
+		page := &storage.Page{}
+		if err := rows.Scan(&page.ID, &page.URL, &page.UserID, &page.Folder, &page.Status, &page.Source, &page.Note, &page.HTTPStatus, &page.CheckFailures, &checked); err != nil {
+			return nil, err
+		}
+		if checked != 0 {
+			page.Checked = time.Unix(checked, 0)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// CountSharedPages() returns the number of links in the shared folder, 0 for users who aren't members
+func (s *Storage) CountSharedPages(ctx context.Context, userID int, id int) (int, error) {
+	q := `SELECT COUNT(*) FROM pages p JOIN members m ON p.userID = m.ownerID AND p.folder = m.folder
+		WHERE m.rowid = ? AND m.userID = ?`
+
+	var count int
+
+	if err := s.db.QueryRowContext(ctx, q, id, userID).Scan(&count); err != nil {
+		return 0, errhandling.Wrap("can't count shared pages", err)
+	}
+
+	return count, nil
+}
+
+// FindSharedFolders() returns the shared folders of the user that contain the link
+func (s *Storage) FindSharedFolders(ctx context.Context, userID int, url string) (folders []storage.SharedFolder, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't find shared folders of page", err) }()
+
+	q := `SELECT m.rowid, m.ownerID, m.folder, m.role FROM members m WHERE m.userID = ? AND EXISTS (SELECT 1 FROM pages p
+		WHERE p.userID = m.ownerID AND p.folder = m.folder AND p.url = ?) ORDER BY m.folder`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var folder storage.SharedFolder
+		if err := rows.Scan(&folder.ID, &folder.OwnerID, &folder.Folder, &folder.Role); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// SaveShared() saves the page to the shared folder on behalf of its owner.
+// The user must be a member with at least the contributor role
+func (s *Storage) SaveShared(ctx context.Context, userID int, id int, p *storage.Page) error {
+	folder, err := s.GetSharedFolder(ctx, userID, id)
+	if err != nil {
+		return errhandling.Wrap("can't save shared page", err)
+	}
+	if folder.Role < storage.RoleContributor {
+		return errhandling.Wrap("can't save shared page", storage.ErrAccessDenied)
+	}
+
+	p.UserID, p.Folder = folder.OwnerID, folder.Folder
+
+	return s.Save(ctx, p)
+}
+
+// GetMembers() returns the members of the folder, without its owner
+func (s *Storage) GetMembers(ctx context.Context, ownerID int, folder string) (members []int, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get members", err) }()
+
+	q := `SELECT userID FROM members WHERE ownerID = ? AND folder = ?`
+
+	rows, err := s.db.QueryContext(ctx, q, ownerID, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var member int
+
+	for rows.Next() {
+		if err := rows.Scan(&member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// role() returns the role of the user in the folder of another user, 0 if the user isn't a member
+func (s *Storage) role(ctx context.Context, userID int, ownerID int, folder string) (storage.Role, error) {
+	q := `SELECT role FROM members WHERE ownerID = ? AND folder = ? AND userID = ?`
+
+	var role storage.Role
+
+	err := s.db.QueryRowContext(ctx, q, ownerID, folder, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return role, err
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+// Столбец запроса к pages: есть ли у ссылки сохраненная копия
+const hasSnapshot = `EXISTS (SELECT 1 FROM snapshots WHERE snapshots.url = pages.url AND snapshots.text != '')`
+
+// GetURLsToSnapshot() returns the links that couldn't be downloaded before retryBefore and are still
+// kept by users who turned on snapshots, the longest waiting first
+func (s *Storage) GetURLsToSnapshot(ctx context.Context, retryBefore time.Time, limit int) (urls []string, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get urls to snapshot", err) }()
+
+	q := `SELECT url FROM snapshots WHERE text = '' AND created < ? AND EXISTS (SELECT 1 FROM pages
+			JOIN users ON users.userID = pages.userID WHERE pages.url = snapshots.url AND users.snapshots = 1)
+		ORDER BY created LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, q, retryBefore.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var url string
+
+	for rows.Next() {
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// SaveSnapshot() saves the copy of the page, the previous copy of the same link is replaced
+func (s *Storage) SaveSnapshot(ctx context.Context, snapshot *storage.Snapshot) error {
+	q := `INSERT INTO snapshots (url, title, text, created) VALUES (?, ?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET title = excluded.title, text = excluded.text, created = excluded.created`
+
+	if _, err := s.db.ExecContext(ctx, q, snapshot.URL, snapshot.Title, snapshot.Text, snapshot.Created.Unix()); err != nil {
+		return errhandling.Wrap("can't save snapshot", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot() returns the copy of the page. Failed downloads are reported as ErrNoSnapshot
+func (s *Storage) GetSnapshot(ctx context.Context, url string) (*storage.Snapshot, error) {
+	q := `SELECT title, text, created FROM snapshots WHERE url = ? AND text != ''`
+
+	snapshot := &storage.Snapshot{URL: url}
+	var created int64
+
+	err := s.db.QueryRowContext(ctx, q, url).Scan(&snapshot.Title, &snapshot.Text, &created)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, errhandling.Wrap("can't get snapshot", err)
+	}
+	snapshot.Created = time.Unix(created, 0)
+
+	return snapshot, nil
+}
+
+// RemoveUnusedSnapshots() deletes copies of the links that nobody keeps anymore
+func (s *Storage) RemoveUnusedSnapshots(ctx context.Context) error {
+	q := `DELETE FROM snapshots WHERE url NOT IN (SELECT url FROM pages)`
+
+	if _, err := s.db.ExecContext(ctx, q); err != nil {
+		return errhandling.Wrap("can't remove unused snapshots", err)
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"strconv"
 
 	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/urlnorm"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -30,18 +32,231 @@ func New(path string) (*Storage, error) {
 
 // Init() create tables in the database
 func (s *Storage) Init(ctx context.Context) error {
-	q := `CREATE TABLE IF NOT EXISTS pages (url TEXT, userID INTEGER, folder TEXT)`
+	q := `CREATE TABLE IF NOT EXISTS pages (url TEXT, userID INTEGER, folder TEXT, status INTEGER DEFAULT 0, source TEXT DEFAULT '', created INTEGER DEFAULT 0, note TEXT DEFAULT '',
+		checkStatus INTEGER DEFAULT 0, checkFailures INTEGER DEFAULT 0, checked INTEGER DEFAULT 0)`
 
 	_, err := s.db.ExecContext(ctx, q)
 	if err != nil {
 		return errhandling.Wrap("can't create table 'pages'", err)
 	}
 
-	q = `CREATE TABLE IF NOT EXISTS folders (userID INTEGER, folder TEXT)`
+	q = `CREATE TABLE IF NOT EXISTS folders (userID INTEGER, folder TEXT, parent TEXT DEFAULT '')`
 	_, err = s.db.ExecContext(ctx, q)
 	if err != nil {
 		return errhandling.Wrap("can't create table 'folders", err)
 	}
 
-	return nil
+	q = `CREATE TABLE IF NOT EXISTS users (userID INTEGER PRIMARY KEY, language TEXT DEFAULT '')`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'users'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS members (ownerID INTEGER, folder TEXT, userID INTEGER, role INTEGER,
+		UNIQUE (ownerID, folder, userID))`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'members'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS invites (code TEXT PRIMARY KEY, ownerID INTEGER, folder TEXT, role INTEGER)`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'invites'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS reminders (userID INTEGER, pageID INTEGER, at INTEGER)`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'reminders'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS digests (userID INTEGER PRIMARY KEY, period INTEGER DEFAULT 0,
+		weekday INTEGER DEFAULT 0, hour INTEGER DEFAULT 0, sent INTEGER DEFAULT 0)`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'digests'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS digestFolders (userID INTEGER, folder TEXT, UNIQUE (userID, folder))`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'digestFolders'", err)
+	}
+
+	q = `CREATE TABLE IF NOT EXISTS snapshots (url TEXT PRIMARY KEY, title TEXT DEFAULT '', text TEXT DEFAULT '', created INTEGER DEFAULT 0)`
+	_, err = s.db.ExecContext(ctx, q)
+	if err != nil {
+		return errhandling.Wrap("can't create table 'snapshots'", err)
+	}
+
+	// Databases created by older versions don't have these columns yet
+	if err := s.addColumn(ctx, "pages", "status", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "folders", "parent", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "source", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "created", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "users", "capture", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "note", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "users", "timezone", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "checkStatus", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "checkFailures", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "pages", "checked", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumn(ctx, "users", "snapshots", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+
+	return s.canonicalizeURLs(ctx)
+}
+
+// Версия базы, в которой все ссылки приведены к каноническому виду
+const canonicalURLsVersion = 1
+
+// canonicalizeURLs() rewrites the links saved before they were stored in the canonical form,
+// so that the duplicate check finds them. Links that become the same in one folder are merged:
+// the oldest stays and gets the reminders of the others. It runs once, the database remembers it
+func (s *Storage) canonicalizeURLs(ctx context.Context) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't canonicalize urls", err) }()
+
+	var version int
+	if err := s.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version >= canonicalURLsVersion {
+		return nil
+	}
+
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		type row struct {
+			id     int
+			url    string
+			userID int
+			folder string
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT rowid, url, userID, folder FROM pages ORDER BY rowid`)
+		if err != nil {
+			return err
+		}
+
+		var pages []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.url, &r.userID, &r.folder); err != nil {
+				rows.Close()
+				return err
+			}
+			pages = append(pages, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		kept := make(map[row]int) // Ссылка в папке -> rowid оставшейся записи
+		renamed := make(map[string]string)
+
+		for _, page := range pages {
+			canonical, err := urlnorm.Canonical(page.url)
+			if err != nil {
+				continue
+			}
+
+			key := row{url: canonical, userID: page.userID, folder: page.folder}
+			if id, ok := kept[key]; ok {
+				if _, err := tx.ExecContext(ctx, `UPDATE reminders SET pageID = ? WHERE pageID = ?`, id, page.id); err != nil {
+					return err
+				}
+				if _, err := tx.ExecContext(ctx, `DELETE FROM pages WHERE rowid = ?`, page.id); err != nil {
+					return err
+				}
+				continue
+			}
+			kept[key] = page.id
+
+			if canonical != page.url {
+				if _, err := tx.ExecContext(ctx, `UPDATE pages SET url = ? WHERE rowid = ?`, canonical, page.id); err != nil {
+					return err
+				}
+				renamed[page.url] = canonical
+			}
+		}
+
+		// Копия остается у ссылки в новом виде, если у той еще нет своей
+		for old, canonical := range renamed {
+			if _, err := tx.ExecContext(ctx, `UPDATE OR IGNORE snapshots SET url = ? WHERE url = ?`, canonical, old); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `PRAGMA user_version = `+strconv.Itoa(canonicalURLsVersion)); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// transaction() runs fn in a transaction. If fn fails, none of its changes are kept
+func (s *Storage) transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errhandling.Wrap("can't begin transaction", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return errhandling.WrapIfErr("can't commit transaction", tx.Commit())
+}
+
+// addColumn() adds a column to the table if it doesn't exist yet
+func (s *Storage) addColumn(ctx context.Context, table, column, definition string) (err error) {
+	defer func() { err = errhandling.WrapIfErr("can't add column '"+column+"' to table '"+table+"'", err) }()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	var name string
+
+	for rows.Next() {
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+definition)
+
+	return err
 }
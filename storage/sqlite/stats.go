@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+	"github.com/hahaclassic/golang-telegram-bot.git/storage"
+)
+
+const week = 7 * 24 * 60 * 60 // Секунд в неделе
+
+// CountPagesByFolder() returns the number of links in every folder of the user, the largest folders first
+func (s *Storage) CountPagesByFolder(ctx context.Context, userID int) (counters []storage.Counter, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't count pages by folder", err) }()
+
+	q := `SELECT folder, COUNT(*) AS n FROM pages WHERE userID = ? GROUP BY folder ORDER BY n DESC, folder`
+
+	return s.counters(ctx, q, userID)
+}
+
+// CountPagesByStatus() returns the number of read, unread and archived links of the user
+func (s *Storage) CountPagesByStatus(ctx context.Context, userID int) (counters map[storage.Status]int, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't count pages by status", err) }()
+
+	q := `SELECT status, COUNT(*) FROM pages WHERE userID = ? GROUP BY status`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counters = make(map[storage.Status]int)
+
+	var (
+		status storage.Status
+		count  int
+	)
+
+	for rows.Next() {
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counters[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
+
+// CountAddedByWeek() returns how many links the user saved in each of the weeks starting at since.
+// Links saved by old versions have no time and aren't counted
+func (s *Storage) CountAddedByWeek(ctx context.Context, userID int, since time.Time, weeks int) (counts []int, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't count pages by week", err) }()
+
+	q := `SELECT (created - ?) / ? AS n, COUNT(*) FROM pages WHERE userID = ? AND created >= ? GROUP BY n`
+
+	rows, err := s.db.QueryContext(ctx, q, since.Unix(), week, userID, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts = make([]int, weeks)
+
+	var n, count int
+
+	for rows.Next() {
+		if err := rows.Scan(&n, &count); err != nil {
+			return nil, err
+		}
+		if n < weeks {
+			counts[n] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// TopDomains() returns the sites the user saves most often. The domain is the host of the link
+// without "www."
+func (s *Storage) TopDomains(ctx context.Context, userID int, limit int) (counters []storage.Counter, err error) {
+	defer func() { err = errhandling.WrapIfErr("can't get top domains", err) }()
+
+	// Хост - часть ссылки между "://" и первым "/" или "?"
+	q := `WITH rest AS (SELECT substr(url, instr(url, '://') + 3) || '/' AS r FROM pages WHERE userID = ?),
+		hosts AS (SELECT lower(substr(r, 1, instr(r, '/') - 1)) AS h FROM rest),
+		clean AS (SELECT CASE WHEN instr(h, '?') > 0 THEN substr(h, 1, instr(h, '?') - 1) ELSE h END AS h FROM hosts)
+		SELECT CASE WHEN h LIKE 'www.%' THEN substr(h, 5) ELSE h END AS domain, COUNT(*) AS n
+		FROM clean GROUP BY domain ORDER BY n DESC, domain LIMIT ?`
+
+	return s.counters(ctx, q, userID, limit)
+}
+
+// GetOldestUnread() returns the unread link that was saved first
+func (s *Storage) GetOldestUnread(ctx context.Context, userID int) (*storage.Page, error) {
+	// У ссылок старых версий нет времени сохранения, они старше остальных
+	q := `SELECT rowid, url, folder, created FROM pages WHERE userID = ? AND status = ? ORDER BY created, rowid LIMIT 1`
+
+	page := &storage.Page{UserID: userID, Status: storage.StatusUnread}
+	var created int64
+
+	err := s.db.QueryRowContext(ctx, q, userID, storage.StatusUnread).Scan(&page.ID, &page.URL, &page.Folder, &created)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSavedPages
+	}
+	if err != nil {
+		return nil, errhandling.Wrap("can't get oldest unread page", err)
+	}
+	if created != 0 {
+		page.Created = time.Unix(created, 0)
+	}
+
+	return page, nil
+}
+
+// counters() runs the query that returns names and numbers
+func (s *Storage) counters(ctx context.Context, q string, args ...any) ([]storage.Counter, error) {
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var counters []storage.Counter
+
+	for rows.Next() {
+		var c storage.Counter
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+		counters = append(counters, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hahaclassic/golang-telegram-bot.git/lib/errhandling"
+)
+
+// GetLanguage() returns the language chosen by the user, "" if the user hasn't chosen it
+func (s *Storage) GetLanguage(ctx context.Context, userID int) (string, error) {
+	q := `SELECT language FROM users WHERE userID = ?`
+
+	var language string
+
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&language)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errhandling.Wrap("can't get language", err)
+	}
+
+	return language, nil
+}
+
+// SetLanguage() saves the language chosen by the user
+func (s *Storage) SetLanguage(ctx context.Context, userID int, language string) error {
+	q := `INSERT INTO users (userID, language) VALUES (?, ?)
+		ON CONFLICT (userID) DO UPDATE SET language = excluded.language`
+
+	if _, err := s.db.ExecContext(ctx, q, userID, language); err != nil {
+		return errhandling.Wrap("can't set language", err)
+	}
+
+	return nil
+}
+
+// IsCaptureEnabled() tells whether links posted in the group are saved automatically.
+// The group is stored as a user with the chat id
+func (s *Storage) IsCaptureEnabled(ctx context.Context, userID int) (bool, error) {
+	q := `SELECT capture FROM users WHERE userID = ?`
+
+	var enabled bool
+
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errhandling.Wrap("can't get capture setting", err)
+	}
+
+	return enabled, nil
+}
+
+// SetCapture() turns on or off saving links posted in the group
+func (s *Storage) SetCapture(ctx context.Context, userID int, enabled bool) error {
+	q := `INSERT INTO users (userID, capture) VALUES (?, ?)
+		ON CONFLICT (userID) DO UPDATE SET capture = excluded.capture`
+
+	if _, err := s.db.ExecContext(ctx, q, userID, enabled); err != nil {
+		return errhandling.Wrap("can't set capture setting", err)
+	}
+
+	return nil
+}
+
+// GetTimezone() returns the time zone chosen by the user, "" if the user hasn't chosen it
+func (s *Storage) GetTimezone(ctx context.Context, userID int) (string, error) {
+	q := `SELECT timezone FROM users WHERE userID = ?`
+
+	var timezone string
+
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&timezone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errhandling.Wrap("can't get timezone", err)
+	}
+
+	return timezone, nil
+}
+
+// SetTimezone() saves the time zone in which the user enters the time of reminders
+func (s *Storage) SetTimezone(ctx context.Context, userID int, timezone string) error {
+	q := `INSERT INTO users (userID, timezone) VALUES (?, ?)
+		ON CONFLICT (userID) DO UPDATE SET timezone = excluded.timezone`
+
+	if _, err := s.db.ExecContext(ctx, q, userID, timezone); err != nil {
+		return errhandling.Wrap("can't set timezone", err)
+	}
+
+	return nil
+}
+
+// IsSnapshotsEnabled() tells whether copies of the pages saved by the user are downloaded
+func (s *Storage) IsSnapshotsEnabled(ctx context.Context, userID int) (bool, error) {
+	q := `SELECT snapshots FROM users WHERE userID = ?`
+
+	var enabled bool
+
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errhandling.Wrap("can't get snapshots setting", err)
+	}
+
+	return enabled, nil
+}
+
+// SetSnapshots() turns on or off downloading copies of the saved pages
+func (s *Storage) SetSnapshots(ctx context.Context, userID int, enabled bool) error {
+	q := `INSERT INTO users (userID, snapshots) VALUES (?, ?)
+		ON CONFLICT (userID) DO UPDATE SET snapshots = excluded.snapshots`
+
+	if _, err := s.db.ExecContext(ctx, q, userID, enabled); err != nil {
+		return errhandling.Wrap("can't set snapshots setting", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 type Storage interface {
@@ -11,6 +12,16 @@ type Storage interface {
 	PickRandom(ctx context.Context, userID int) (*Page, error)
 	Remove(ctx context.Context, p *Page) error
 	IsExist(ctx context.Context, p *Page) (bool, error)
+	FindFolders(ctx context.Context, userID int, url string) ([]string, error)
+	GetPage(ctx context.Context, userID int, id int) (*Page, error)
+	SetStatus(ctx context.Context, userID int, id int, status Status) error
+	SetNote(ctx context.Context, userID int, id int, note string) error
+	GetUnread(ctx context.Context, userID int, limit, offset int) ([]*Page, error)
+	CountUnreadPages(ctx context.Context, userID int) (int, error)
+	GetPages(ctx context.Context, userID int, folder string, limit, offset int) ([]*Page, error)
+	CountPages(ctx context.Context, userID int, folder string) (int, error)
+	GetAllPages(ctx context.Context, userID int) ([]*Page, error)
+	Search(ctx context.Context, userID int, query string, limit, offset int) ([]*Page, error)
 
 	NewFolder(ctx context.Context, userID int, folder string) error
 	RemoveFolder(ctx context.Context, userID int, folder string) error
@@ -18,12 +29,170 @@ type Storage interface {
 	GetListOfFolders(ctx context.Context, userID int) (names []string, err error)
 	IsFolderExist(ctx context.Context, userID int, folder string) (bool, error)
 	RenameFolder(ctx context.Context, userID int, newFolder, oldFolder string) error
+	GetFoldersPage(ctx context.Context, userID int, limit, offset int) ([]string, error)
+	CountFolders(ctx context.Context, userID int) (int, error)
+	GetSubfolders(ctx context.Context, userID int, parent string, limit, offset int) ([]Folder, error)
+	CountSubfolders(ctx context.Context, userID int, parent string) (int, error)
+	GetFolderID(ctx context.Context, userID int, folder string) (int, error)
+	GetFolderByID(ctx context.Context, userID int, id int) (string, error)
+	CountUnread(ctx context.Context, userID int) (map[string]int, error)
+
+	GetLanguage(ctx context.Context, userID int) (string, error)
+	SetLanguage(ctx context.Context, userID int, language string) error
+	IsCaptureEnabled(ctx context.Context, userID int) (bool, error)
+	SetCapture(ctx context.Context, userID int, enabled bool) error
+	GetTimezone(ctx context.Context, userID int) (string, error)
+	SetTimezone(ctx context.Context, userID int, timezone string) error
+	IsSnapshotsEnabled(ctx context.Context, userID int) (bool, error)
+	SetSnapshots(ctx context.Context, userID int, enabled bool) error
+
+	AddReminder(ctx context.Context, r *Reminder) error
+	GetDueReminders(ctx context.Context, now time.Time) ([]Reminder, error)
+	RemoveReminder(ctx context.Context, id int) error
+
+	// Методы проверки ссылок работают со ссылками всех пользователей
+	GetPagesToCheck(ctx context.Context, before time.Time, limit int) ([]*Page, error)
+	SetCheckResult(ctx context.Context, id int, status int, failed bool, checked time.Time) error
+	GetDeadPages(ctx context.Context, userID int, limit, offset int) ([]*Page, error)
+	CountDeadPages(ctx context.Context, userID int) (int, error)
+	RemoveDeadPages(ctx context.Context, userID int) (int, error)
+
+	// Статистика ссылок пользователя
+	CountPagesByFolder(ctx context.Context, userID int) ([]Counter, error)
+	CountPagesByStatus(ctx context.Context, userID int) (map[Status]int, error)
+	CountAddedByWeek(ctx context.Context, userID int, since time.Time, weeks int) ([]int, error)
+	TopDomains(ctx context.Context, userID int, limit int) ([]Counter, error)
+	GetOldestUnread(ctx context.Context, userID int) (*Page, error)
+
+	// Копия страницы одна для всех пользователей, которые сохранили ссылку
+	GetURLsToSnapshot(ctx context.Context, retryBefore time.Time, limit int) ([]string, error)
+	SaveSnapshot(ctx context.Context, s *Snapshot) error
+	GetSnapshot(ctx context.Context, url string) (*Snapshot, error)
+	RemoveUnusedSnapshots(ctx context.Context) error
+
+	GetDigest(ctx context.Context, userID int) (*Digest, error)
+	SetDigest(ctx context.Context, d *Digest) error
+	SetDigestFolders(ctx context.Context, userID int, folders []string) error
+	SetDigestSent(ctx context.Context, userID int, sent time.Time) error
+	GetDigests(ctx context.Context) ([]Digest, error)
+	GetDigestPages(ctx context.Context, userID int, limit int) ([]*Page, error)
+
+	// Методы общих папок проверяют права userID сами
+	CreateInvite(ctx context.Context, userID int, folder SharedFolder, role Role, code string) error
+	JoinFolder(ctx context.Context, userID int, code string) (*SharedFolder, error)
+	GetSharedFolders(ctx context.Context, userID int, minRole Role) ([]SharedFolder, error)
+	GetSharedFolder(ctx context.Context, userID int, id int) (*SharedFolder, error)
+	GetSharedPages(ctx context.Context, userID int, id int, limit, offset int) ([]*Page, error)
+	CountSharedPages(ctx context.Context, userID int, id int) (int, error)
+	FindSharedFolders(ctx context.Context, userID int, url string) ([]SharedFolder, error)
+	SaveShared(ctx context.Context, userID int, id int, p *Page) error
+	GetMembers(ctx context.Context, ownerID int, folder string) ([]int, error)
 }
 
-var ErrNoSavedPages = errors.New("no saved pages")
+var (
+	ErrNoSavedPages   = errors.New("no saved pages")
+	ErrPageNotFound   = errors.New("page not found")
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrAccessDenied   = errors.New("access denied")
+	ErrInviteNotFound = errors.New("invite not found")
+	ErrNoSnapshot     = errors.New("snapshot not found")
+)
+
+// Одна неудачная проверка может быть случайным сбоем сети или сайта,
+// ссылка считается битой после стольких неудачных проверок подряд
+const DeadAfterFailures = 2
+
+// Status describes whether the page has been read
+type Status int
+
+const (
+	StatusUnread Status = iota
+	StatusRead
+	StatusArchived
+)
 
 type Page struct {
-	URL    string
+	ID      int
+	URL     string
+	UserID  int
+	Folder  string
+	Status  Status
+	Source  string    // Ссылка на пост, из которого переслана ссылка
+	Created time.Time // Время сохранения. Нулевое для ссылок, сохраненных старыми версиями
+	Note    string    // Заметка пользователя о том, зачем сохранена ссылка
+
+	HTTPStatus    int       // Код ответа при последней проверке ссылки, 0 - сайт не ответил
+	CheckFailures int       // Неудачных проверок подряд, ссылка битая после DeadAfterFailures
+	Checked       time.Time // Время последней проверки. Нулевое для непроверенных ссылок
+
+	Snapshot bool // Есть сохраненная копия страницы
+}
+
+// Counter is the number of links that have something in common: a folder or a site
+type Counter struct {
+	Name  string
+	Count int
+}
+
+// Folder is a node of the folder tree. Path contains names of all parent folders: "Work/Go/Talks"
+type Folder struct {
+	Path          string
+	HasSubfolders bool
+}
+
+// Role is the access level of a member of a shared folder. Roles are ordered:
+// every role can do everything the previous one can
+type Role int
+
+const (
+	RoleViewer      Role = iota + 1 // Просматривает ссылки
+	RoleContributor                 // Добавляет ссылки
+	RoleOwner                       // Приглашает других участников
+)
+
+// SharedFolder is a folder of another user that the member has joined by an invite.
+// ID identifies the membership, it is 0 for the folder of the user himself
+type SharedFolder struct {
+	ID      int
+	OwnerID int
+	Folder  string
+	Role    Role
+}
+
+// Reminder asks to send the saved link to its owner again at the given time.
+// Links of a group are sent to the group chat
+type Reminder struct {
+	ID     int
 	UserID int
-	Folder string
+	PageID int
+	At     time.Time
+}
+
+// Snapshot is the readable copy of the page saved in case the original disappears.
+// Empty Text means that the page couldn't be downloaded at Created
+type Snapshot struct {
+	URL     string
+	Title   string
+	Text    string
+	Created time.Time
+}
+
+// DigestPeriod tells how often the digest of saved links is sent
+type DigestPeriod int
+
+const (
+	DigestOff DigestPeriod = iota
+	DigestDaily
+	DigestWeekly
+)
+
+// Digest is the schedule of the periodic message with saved links.
+// Weekday and Hour are in the time zone of the user
+type Digest struct {
+	UserID  int
+	Period  DigestPeriod
+	Weekday time.Weekday // Только для еженедельного дайджеста
+	Hour    int
+	Folders []string  // Папки, из которых берутся ссылки, вместе с вложенными. Пустой список - все папки
+	Sent    time.Time // Последняя отправка или изменение расписания
 }